@@ -0,0 +1,255 @@
+package chrome
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+const (
+	// DefaultAuthURL is the Google OAuth 2.0 consent page URL.
+	DefaultAuthURL = "https://accounts.google.com/o/oauth2/v2/auth"
+	// DefaultTokenURL is the Google OAuth 2.0 token endpoint URL.
+	DefaultTokenURL = "https://oauth2.googleapis.com/token"
+	// Scope is the OAuth 2.0 scope of the Chrome Web Store API.
+	Scope = "https://www.googleapis.com/auth/chromewebstore"
+)
+
+// DefaultLoopbackTimeout is the default time to wait for the user to grant
+// the consent.
+const DefaultLoopbackTimeout = 5 * time.Minute
+
+// pkceVerifierSize is the size of the random PKCE code verifier in bytes.
+// 32 bytes are encoded to 43 characters, the minimum allowed by RFC 7636.
+const pkceVerifierSize = 32
+
+// LoopbackFlowConfig describes the OAuth 2.0 authorization code flow with a
+// loopback redirect and PKCE, used to obtain a refresh token.
+type LoopbackFlowConfig struct {
+	// Logger is used to log the progress of the flow.
+	Logger *slog.Logger
+
+	// PrintURL is called with the consent URL the user must open in the
+	// browser.
+	PrintURL func(consentURL string)
+
+	// AuthURL is the consent page URL.  If empty, DefaultAuthURL is used.
+	AuthURL string
+
+	// TokenURL is the token endpoint URL.  If empty, DefaultTokenURL is used.
+	TokenURL string
+
+	// ClientID is the ID of the OAuth client of the "Desktop app" type.
+	ClientID string
+
+	// ClientSecret is the secret of the OAuth client.
+	ClientSecret string
+
+	// Timeout is the time to wait for the consent.  If zero,
+	// DefaultLoopbackTimeout is used.
+	Timeout time.Duration
+}
+
+// tokenResponse describes the response of the token endpoint to the
+// authorization code exchange.
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// authCallback is the result of the redirect to the loopback listener.
+type authCallback struct {
+	code string
+	err  error
+}
+
+// RunLoopbackFlow starts a local HTTP listener, asks the user to open the
+// consent URL and exchanges the authorization code received by the listener
+// for a refresh token.
+// https://developers.google.com/identity/protocols/oauth2/native-app
+func RunLoopbackFlow(ctx context.Context, conf LoopbackFlowConfig) (refreshToken string, err error) {
+	authURL := cmp.Or(conf.AuthURL, DefaultAuthURL)
+	tokenURL := cmp.Or(conf.TokenURL, DefaultTokenURL)
+	timeout := conf.Timeout
+	if timeout <= 0 {
+		timeout = DefaultLoopbackTimeout
+	}
+
+	l := conf.Logger.With("action", "RunLoopbackFlow")
+
+	verifier, err := randomString(pkceVerifierSize)
+	if err != nil {
+		return "", fmt.Errorf("generating code verifier: %w", err)
+	}
+
+	state, err := randomString(pkceVerifierSize)
+	if err != nil {
+		return "", fmt.Errorf("generating state: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("starting loopback listener: %w", err)
+	}
+
+	redirectURI := "http://" + listener.Addr().String() + "/"
+	callbacks := make(chan authCallback, 1)
+	srv := &http.Server{
+		Handler:           callbackHandler(state, callbacks),
+		ReadHeaderTimeout: requestTimeout,
+	}
+
+	go func() {
+		serveErr := srv.Serve(listener)
+		if errors.Is(serveErr, http.ErrServerClosed) {
+			return
+		}
+
+		select {
+		case callbacks <- authCallback{err: fmt.Errorf("serving loopback listener: %w", serveErr)}:
+		default:
+		}
+	}()
+	defer func() { err = errors.WithDeferred(err, srv.Close()) }()
+
+	l.Debug("waiting for consent", "redirect_uri", redirectURI)
+	conf.PrintURL(consentURL(authURL, conf.ClientID, redirectURI, state, pkceChallenge(verifier)))
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var cb authCallback
+	select {
+	case cb = <-callbacks:
+	case <-ctx.Done():
+		return "", fmt.Errorf("waiting for consent: %w", ctx.Err())
+	}
+
+	if cb.err != nil {
+		return "", cb.err
+	}
+
+	l.Debug("authorization code received, exchanging")
+
+	return exchangeCode(tokenURL, conf.ClientID, conf.ClientSecret, cb.code, verifier, redirectURI)
+}
+
+// consentURL returns the URL of the consent page.  Offline access and the
+// consent prompt are requested, so that a refresh token is always returned.
+func consentURL(authURL, clientID, redirectURI, state, challenge string) (u string) {
+	q := url.Values{
+		"client_id":             {clientID},
+		"redirect_uri":          {redirectURI},
+		"response_type":         {"code"},
+		"scope":                 {Scope},
+		"access_type":           {"offline"},
+		"prompt":                {"consent"},
+		"state":                 {state},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	return authURL + "?" + q.Encode()
+}
+
+// callbackHandler returns the handler of the redirect to the loopback
+// listener, which sends the result to callbacks once.
+func callbackHandler(state string, callbacks chan<- authCallback) (h http.Handler) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		q := r.URL.Query()
+
+		var cb authCallback
+		switch {
+		case q.Get("state") != state:
+			cb.err = errors.Error("state mismatch in the authorization response")
+		case q.Get("error") != "":
+			cb.err = fmt.Errorf("authorization denied: %s", q.Get("error"))
+		case q.Get("code") == "":
+			cb.err = errors.Error("no authorization code in the authorization response")
+		default:
+			cb.code = q.Get("code")
+		}
+
+		msg := "Authorization completed, you may close this window."
+		if cb.err != nil {
+			msg = "Authorization failed: " + cb.err.Error()
+		}
+
+		_, _ = fmt.Fprintln(w, msg)
+
+		select {
+		case callbacks <- cb:
+		default:
+			// The flow is already completed.
+		}
+	})
+}
+
+// exchangeCode exchanges the authorization code for the refresh token.
+func exchangeCode(tokenURL, clientID, clientSecret, code, verifier, redirectURI string) (refreshToken string, err error) {
+	data := url.Values{
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+		"code":          {code},
+		"code_verifier": {verifier},
+		"grant_type":    {"authorization_code"},
+		"redirect_uri":  {redirectURI},
+	}
+
+	result := &tokenResponse{}
+	err = makeRequest(
+		http.MethodPost,
+		tokenURL,
+		"", // no access token
+		requestTimeout,
+		result,
+		&RequestOptions{
+			Body:        strings.NewReader(data.Encode()),
+			ContentType: "application/x-www-form-urlencoded",
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("exchanging authorization code: %w", err)
+	}
+
+	if result.RefreshToken == "" {
+		return "", errors.Error("no refresh token in the token response")
+	}
+
+	return result.RefreshToken, nil
+}
+
+// pkceChallenge returns the S256 code challenge for the verifier.
+func pkceChallenge(verifier string) (challenge string) {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// randomString returns a random URL-safe string of n random bytes.
+func randomString(n int) (s string, err error) {
+	b := make([]byte, n)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
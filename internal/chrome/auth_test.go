@@ -0,0 +1,80 @@
+package chrome_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/adguardteam/go-webext/internal/chrome"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunLoopbackFlow(t *testing.T) {
+	const code = "test_code"
+
+	var challenge string
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pt := testutil.PanicT{}
+
+		assert.Equal(pt, http.MethodPost, r.Method)
+		assert.Equal(pt, "authorization_code", r.FormValue("grant_type"))
+		assert.Equal(pt, code, r.FormValue("code"))
+		assert.Equal(pt, clientID, r.FormValue("client_id"))
+		assert.Equal(pt, clientSecret, r.FormValue("client_secret"))
+
+		sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		assert.Equal(pt, challenge, base64.RawURLEncoding.EncodeToString(sum[:]))
+
+		err := json.NewEncoder(w).Encode(map[string]string{
+			"access_token":  accessToken,
+			"refresh_token": refreshToken,
+		})
+		require.NoError(pt, err)
+	}))
+	defer tokenServer.Close()
+
+	// simulateBrowser follows the consent URL the way the browser does after
+	// the user grants the consent.
+	simulateBrowser := func(consentURL string) {
+		u, err := url.Parse(consentURL)
+		require.NoError(t, err)
+
+		q := u.Query()
+		assert.Equal(t, chrome.Scope, q.Get("scope"))
+		assert.Equal(t, "offline", q.Get("access_type"))
+		assert.Equal(t, "S256", q.Get("code_challenge_method"))
+		challenge = q.Get("code_challenge")
+
+		redirect, err := url.Parse(q.Get("redirect_uri"))
+		require.NoError(t, err)
+
+		redirect.RawQuery = url.Values{"code": {code}, "state": {q.Get("state")}}.Encode()
+
+		go func() {
+			res, getErr := http.Get(redirect.String())
+			if getErr == nil {
+				_ = res.Body.Close()
+			}
+		}()
+	}
+
+	token, err := chrome.RunLoopbackFlow(context.Background(), chrome.LoopbackFlowConfig{
+		Logger:       slogutil.NewDiscardLogger(),
+		PrintURL:     simulateBrowser,
+		AuthURL:      "https://accounts.example/auth",
+		TokenURL:     tokenServer.URL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, refreshToken, token)
+}
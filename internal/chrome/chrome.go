@@ -14,6 +14,7 @@ import (
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/httphdr"
 	"github.com/adguardteam/go-webext/internal/fileutil"
+	"github.com/adguardteam/go-webext/internal/tokencache"
 )
 
 const (
@@ -26,6 +27,18 @@ const (
 	maxReadLimit = 100 * fileutil.MB
 )
 
+// Authorizer returns access tokens for the Chrome Web Store API.  It's
+// implemented by *Client, which uses the refresh token of a user, and by
+// *ServiceAccountClient.
+type Authorizer interface {
+	// Authorize returns a valid access token.  It must be safe for concurrent
+	// use.
+	Authorize() (accessToken string, err error)
+}
+
+// type check
+var _ Authorizer = (*Client)(nil)
+
 // Client describes structure of a Chrome Store API client.
 type Client struct {
 	url          string
@@ -33,6 +46,7 @@ type Client struct {
 	clientSecret string
 	refreshToken string
 	logger       *slog.Logger
+	tokens       *tokencache.Cache
 }
 
 // ClientConfig contains configuration parameters for creating a Chrome extension store instance
@@ -42,6 +56,9 @@ type ClientConfig struct {
 	ClientSecret string
 	RefreshToken string
 	Logger       *slog.Logger
+	// Now returns the current time, it's used to check the expiry of the
+	// cached access token.  If nil, time.Now is used.
+	Now func() time.Time
 }
 
 // NewClient creates a new Chrome extension store instance
@@ -52,6 +69,7 @@ func NewClient(config ClientConfig) *Client {
 		clientSecret: config.ClientSecret,
 		refreshToken: config.RefreshToken,
 		logger:       config.Logger,
+		tokens:       tokencache.New(config.Now),
 	}
 }
 
@@ -154,10 +172,19 @@ func makeZipRequest(
 // authorization request.
 type AuthorizeResponse struct {
 	AccessToken string `json:"access_token"`
+	// ExpiresIn is the lifetime of the access token in seconds.
+	ExpiresIn int `json:"expires_in"`
 }
 
-// Authorize retrieves access token.
+// Authorize returns the access token.  The token is cached until it's about to
+// expire, so that subsequent calls don't refresh it.  It's safe for concurrent
+// use.
 func (c *Client) Authorize() (accessToken string, err error) {
+	return c.tokens.Token(c.refreshAccessToken)
+}
+
+// refreshAccessToken retrieves a new access token using the refresh token.
+func (c *Client) refreshAccessToken() (accessToken string, expiresIn time.Duration, err error) {
 	l := c.logger.With("action", "Authorize")
 	l.Debug("initiating authorization")
 
@@ -182,13 +209,16 @@ func (c *Client) Authorize() (accessToken string, err error) {
 		},
 	)
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 
+	expiresIn = time.Duration(result.ExpiresIn) * time.Second
+
 	l.Debug(
 		"authorization completed",
 		"status", "success",
+		"expires_in", expiresIn,
 	)
 
-	return result.AccessToken, nil
+	return result.AccessToken, expiresIn, nil
 }
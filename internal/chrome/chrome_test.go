@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/AdguardTeam/golibs/httphdr"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
@@ -318,6 +320,50 @@ func TestAuthorizeV2(t *testing.T) {
 	assert.Equal(t, accessToken, result, "Tokens should be equal")
 }
 
+func TestAuthorize_cache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+
+		expectedJSON, err := json.Marshal(map[string]any{
+			"access_token": accessToken + strconv.Itoa(requests),
+			"expires_in":   3600,
+		})
+		require.NoError(t, err)
+
+		_, err = w.Write(expectedJSON)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	client := chrome.NewClient(chrome.ClientConfig{
+		URL:          server.URL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RefreshToken: refreshToken,
+		Logger:       slogutil.NewDiscardLogger(),
+		Now:          func() time.Time { return now },
+	})
+
+	for range 3 {
+		result, err := client.Authorize()
+		require.NoError(t, err)
+
+		assert.Equal(t, accessToken+"1", result)
+	}
+
+	assert.Equal(t, 1, requests)
+
+	now = now.Add(time.Hour)
+
+	result, err := client.Authorize()
+	require.NoError(t, err)
+
+	assert.Equal(t, accessToken+"2", result)
+	assert.Equal(t, 2, requests)
+}
+
 func TestStatusV2(t *testing.T) {
 	status := chrome.StatusResponse{
 		Name:      "publishers/" + publisherID + "/items/" + itemID,
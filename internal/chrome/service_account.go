@@ -0,0 +1,172 @@
+package chrome
+
+import (
+	"cmp"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/adguardteam/go-webext/internal/tokencache"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// jwtBearerGrantType is the grant type of the OAuth 2.0 JWT bearer flow.
+// https://developers.google.com/identity/protocols/oauth2/service-account#httprest
+const jwtBearerGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+
+// assertionLifetime is the lifetime of the signed assertion, the maximum
+// accepted by Google.
+const assertionLifetime = time.Hour
+
+// ServiceAccountKey describes the JSON key of a Google service account.
+type ServiceAccountKey struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// ParseServiceAccountKey parses and validates the JSON key of a Google
+// service account.
+func ParseServiceAccountKey(data []byte) (key *ServiceAccountKey, err error) {
+	key = &ServiceAccountKey{}
+	err = json.Unmarshal(data, key)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling service account key: %w", err)
+	}
+
+	if key.Type != "service_account" {
+		return nil, fmt.Errorf("unexpected key type %q, want %q", key.Type, "service_account")
+	}
+
+	if key.ClientEmail == "" || key.PrivateKey == "" {
+		return nil, errors.Error("service account key must contain client_email and private_key")
+	}
+
+	return key, nil
+}
+
+// ServiceAccountClientConfig contains configuration parameters for creating a
+// service account client.
+type ServiceAccountClientConfig struct {
+	// Key is the JSON key of the service account.
+	Key *ServiceAccountKey
+	// TokenURL is the token endpoint URL.  If empty, the token_uri of the key
+	// or DefaultTokenURL is used.
+	TokenURL string
+	Logger   *slog.Logger
+	// Now returns the current time, it's used to sign the assertion and to
+	// check the expiry of the cached access token.  If nil, time.Now is used.
+	Now func() time.Time
+}
+
+// ServiceAccountClient retrieves access tokens of a Google service account
+// using the JWT bearer grant with the assertion signed locally by its private
+// key.
+type ServiceAccountClient struct {
+	privateKey   *rsa.PrivateKey
+	logger       *slog.Logger
+	now          func() time.Time
+	tokens       *tokencache.Cache
+	tokenURL     string
+	clientEmail  string
+	privateKeyID string
+}
+
+// type check
+var _ Authorizer = (*ServiceAccountClient)(nil)
+
+// NewServiceAccountClient creates a new service account client.  It returns an
+// error if the private key of the service account can't be parsed.
+func NewServiceAccountClient(config ServiceAccountClientConfig) (c *ServiceAccountClient, err error) {
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(config.Key.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %w", err)
+	}
+
+	now := config.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	return &ServiceAccountClient{
+		privateKey:   privateKey,
+		logger:       config.Logger,
+		now:          now,
+		tokens:       tokencache.New(now),
+		tokenURL:     cmp.Or(config.TokenURL, config.Key.TokenURI, DefaultTokenURL),
+		clientEmail:  config.Key.ClientEmail,
+		privateKeyID: config.Key.PrivateKeyID,
+	}, nil
+}
+
+// Authorize implements the Authorizer interface for *ServiceAccountClient.
+// The token is cached until it's about to expire.
+func (c *ServiceAccountClient) Authorize() (accessToken string, err error) {
+	return c.tokens.Token(c.requestAccessToken)
+}
+
+// requestAccessToken exchanges a newly signed assertion for an access token.
+func (c *ServiceAccountClient) requestAccessToken() (accessToken string, expiresIn time.Duration, err error) {
+	l := c.logger.With("action", "Authorize", "client_email", c.clientEmail)
+	l.Debug("initiating service account authorization")
+
+	assertion, err := c.assertion()
+	if err != nil {
+		return "", 0, fmt.Errorf("signing assertion: %w", err)
+	}
+
+	data := url.Values{
+		"grant_type": {jwtBearerGrantType},
+		"assertion":  {assertion},
+	}
+
+	result := &AuthorizeResponse{}
+	err = makeRequest(
+		http.MethodPost,
+		c.tokenURL,
+		"", // no access token
+		requestTimeout,
+		result,
+		&RequestOptions{
+			Body:        strings.NewReader(data.Encode()),
+			ContentType: "application/x-www-form-urlencoded",
+		},
+	)
+	if err != nil {
+		return "", 0, err
+	}
+
+	expiresIn = time.Duration(result.ExpiresIn) * time.Second
+
+	l.Debug("service account authorization completed", "expires_in", expiresIn)
+
+	return result.AccessToken, expiresIn, nil
+}
+
+// assertion returns the JWT asserting the identity of the service account.
+func (c *ServiceAccountClient) assertion() (signed string, err error) {
+	now := c.now()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   c.clientEmail,
+		"scope": Scope,
+		"aud":   c.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(assertionLifetime).Unix(),
+	})
+
+	if c.privateKeyID != "" {
+		token.Header["kid"] = c.privateKeyID
+	}
+
+	return token.SignedString(c.privateKey)
+}
@@ -0,0 +1,103 @@
+package chrome_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/adguardteam/go-webext/internal/chrome"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testClientEmail = "ci@test-project.iam.gserviceaccount.com"
+
+// newTestServiceAccountKey returns the JSON key of a service account with a
+// newly generated private key.
+func newTestServiceAccountKey(t *testing.T, tokenURI string) (data []byte, privateKey *rsa.PrivateKey) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	data, err = json.Marshal(chrome.ServiceAccountKey{
+		Type:         "service_account",
+		ProjectID:    "test-project",
+		PrivateKeyID: "test-key-id",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ClientEmail:  testClientEmail,
+		TokenURI:     tokenURI,
+	})
+	require.NoError(t, err)
+
+	return data, privateKey
+}
+
+func TestServiceAccountClient_Authorize(t *testing.T) {
+	var privateKey *rsa.PrivateKey
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pt := testutil.PanicT{}
+		requests++
+
+		assert.Equal(pt, http.MethodPost, r.Method)
+		assert.Equal(pt, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.FormValue("grant_type"))
+
+		claims := jwt.MapClaims{}
+		token, err := jwt.ParseWithClaims(r.FormValue("assertion"), claims, func(_ *jwt.Token) (any, error) {
+			return &privateKey.PublicKey, nil
+		}, jwt.WithValidMethods([]string{"RS256"}))
+		require.NoError(pt, err)
+
+		assert.Equal(pt, "test-key-id", token.Header["kid"])
+		assert.Equal(pt, testClientEmail, claims["iss"])
+		assert.Equal(pt, chrome.Scope, claims["scope"])
+
+		err = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": accessToken,
+			"expires_in":   3600,
+		})
+		require.NoError(pt, err)
+	}))
+	defer server.Close()
+
+	data, privateKey := newTestServiceAccountKey(t, server.URL)
+
+	key, err := chrome.ParseServiceAccountKey(data)
+	require.NoError(t, err)
+
+	client, err := chrome.NewServiceAccountClient(chrome.ServiceAccountClientConfig{
+		Key:    key,
+		Logger: slogutil.NewDiscardLogger(),
+	})
+	require.NoError(t, err)
+
+	for range 2 {
+		token, authErr := client.Authorize()
+		require.NoError(t, authErr)
+
+		assert.Equal(t, accessToken, token)
+	}
+
+	assert.Equal(t, 1, requests)
+}
+
+func TestParseServiceAccountKey_invalid(t *testing.T) {
+	_, err := chrome.ParseServiceAccountKey([]byte(`{"type": "authorized_user"}`))
+	assert.Error(t, err)
+
+	_, err = chrome.ParseServiceAccountKey([]byte(`{"type": "service_account"}`))
+	assert.Error(t, err)
+}
@@ -14,14 +14,14 @@ import (
 
 // StoreV1 implements Chrome Web Store API v1.1.
 type StoreV1 struct {
-	client *Client
+	client Authorizer
 	url    *url.URL
 	logger *slog.Logger
 }
 
 // StoreV1Config contains configuration parameters for creating a Chrome extension store v1 instance.
 type StoreV1Config struct {
-	Client *Client
+	Client Authorizer
 	URL    *url.URL
 	Logger *slog.Logger
 }
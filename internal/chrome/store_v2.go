@@ -15,7 +15,7 @@ import (
 
 // StoreV2 implements Chrome Web Store API v2.
 type StoreV2 struct {
-	client      *Client
+	client      Authorizer
 	url         *url.URL
 	publisherID string
 	logger      *slog.Logger
@@ -23,7 +23,7 @@ type StoreV2 struct {
 
 // StoreV2Config contains configuration parameters for creating a Chrome extension store v2 instance.
 type StoreV2Config struct {
-	Client      *Client
+	Client      Authorizer
 	URL         *url.URL
 	PublisherID string
 	Logger      *slog.Logger
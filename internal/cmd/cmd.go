@@ -2,19 +2,28 @@
 package cmd
 
 import (
+	"cmp"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
 	"time"
 
+	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/validate"
 	"github.com/adguardteam/go-webext/internal/chrome"
+	"github.com/adguardteam/go-webext/internal/credentials"
 	"github.com/adguardteam/go-webext/internal/edge"
+	"github.com/adguardteam/go-webext/internal/extstore"
+	"github.com/adguardteam/go-webext/internal/fileutil"
 	"github.com/adguardteam/go-webext/internal/firefox"
 	firefoxapi "github.com/adguardteam/go-webext/internal/firefox/api"
-	"github.com/caarlos0/env/v6"
+	"github.com/adguardteam/go-webext/internal/profile"
 	"github.com/joho/godotenv"
 	"github.com/urfave/cli/v2"
 )
@@ -24,81 +33,188 @@ const (
 	chromeAPIVersionV2 = "v2"
 )
 
+// Output formats supported by the commands printing reports and lists.
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// Keys of the values set up in Main in the metadata of the app.
+const (
+	// credentialsMetadataKey is the key of the *credentials.Resolver.
+	credentialsMetadataKey = "credentials"
+	// profileMetadataKey is the key of the selected *profile.Profile, if any.
+	profileMetadataKey = "profile"
+)
+
+// parseCredentials fills the config struct pointed to by v from the
+// credentials resolved by the providers set up in Main.
+func parseCredentials(c *cli.Context, v any) (err error) {
+	r, ok := c.App.Metadata[credentialsMetadataKey].(*credentials.Resolver)
+	if !ok {
+		return errors.Error("credentials resolver is not initialized")
+	}
+
+	return r.Parse(v)
+}
+
+// getAppID returns the item ID from the --app flag or, if it's not set, from
+// the selected profile for the given store.
+func getAppID(c *cli.Context, store string) (appID string, err error) {
+	appID = cmp.Or(c.String("app"), profileAppID(c, store))
+	if appID == "" {
+		return "", fmt.Errorf("--app is required unless the profile sets the %s item id", store)
+	}
+
+	return appID, nil
+}
+
+// profileAppID returns the item ID for the given store from the selected
+// profile, or an empty string if there is none.
+func profileAppID(c *cli.Context, store string) (appID string) {
+	if p, _ := c.App.Metadata[profileMetadataKey].(*profile.Profile); p != nil {
+		return p.AppID(store)
+	}
+
+	return ""
+}
+
+// loadProfile returns the values of the profile selected with --profile from
+// the config file, or nil if no profile is selected.
+func loadProfile(c *cli.Context) (p *profile.Profile, err error) {
+	name := c.String("profile")
+	if name == "" {
+		return nil, nil
+	}
+
+	conf, err := profile.Load(c.String("config"))
+	if err != nil {
+		return nil, err
+	}
+
+	return conf.Profile(name)
+}
+
 type chromeConfig struct {
-	ClientID     string `env:"CHROME_CLIENT_ID,notEmpty"`
-	ClientSecret string `env:"CHROME_CLIENT_SECRET,notEmpty"`
-	RefreshToken string `env:"CHROME_REFRESH_TOKEN,notEmpty"`
-	PublisherID  string `env:"CHROME_PUBLISHER_ID"` // Required only for v2
-	APIVersion   string `env:"CHROME_API_VERSION" envDefault:"v1"`
+	// ClientID, ClientSecret and RefreshToken are required unless a service
+	// account key is set.
+	ClientID     string `env:"CHROME_CLIENT_ID" secret:"true"`
+	ClientSecret string `env:"CHROME_CLIENT_SECRET" secret:"true"`
+	RefreshToken string `env:"CHROME_REFRESH_TOKEN" secret:"true"`
+	// ServiceAccountKeyFile is the path to the JSON key of a service account.
+	ServiceAccountKeyFile string `env:"CHROME_SERVICE_ACCOUNT_KEY_FILE"`
+	// ServiceAccountKey is the JSON key of a service account itself, it takes
+	// precedence over ServiceAccountKeyFile.
+	ServiceAccountKey string `env:"CHROME_SERVICE_ACCOUNT_KEY" secret:"true"`
+	PublisherID       string `env:"CHROME_PUBLISHER_ID" secret:"true"` // Required only for v2
+	APIVersion        string `env:"CHROME_API_VERSION" envDefault:"v1"`
 }
 
-func newChromeConfig() (*chromeConfig, error) {
+func newChromeConfig(c *cli.Context) (*chromeConfig, error) {
 	cfg := &chromeConfig{}
-	if err := env.Parse(cfg); err != nil {
+	if err := parseCredentials(c, cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse Chrome environment variables: %w", err)
 	}
 	return cfg, nil
 }
 
-func getChromeV1Store() (*chrome.StoreV1, error) {
-	cfg, err := newChromeConfig()
+// hasServiceAccount returns true if the service account key is configured.
+func (cfg *chromeConfig) hasServiceAccount() (ok bool) {
+	return cfg.ServiceAccountKey != "" || cfg.ServiceAccountKeyFile != ""
+}
+
+// newChromeAuthorizer returns the authorizer for the configured credentials:
+// the service account if its key is set, the refresh token of a user
+// otherwise.
+func newChromeAuthorizer(cfg *chromeConfig, logger *slog.Logger) (a chrome.Authorizer, err error) {
+	if !cfg.hasServiceAccount() {
+		err = errors.Join(
+			validate.NotEmpty("CHROME_CLIENT_ID", cfg.ClientID),
+			validate.NotEmpty("CHROME_CLIENT_SECRET", cfg.ClientSecret),
+			validate.NotEmpty("CHROME_REFRESH_TOKEN", cfg.RefreshToken),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("chrome credentials: %w (or set CHROME_SERVICE_ACCOUNT_KEY_FILE)", err)
+		}
+
+		return chrome.NewClient(chrome.ClientConfig{
+			URL:          "https://accounts.google.com/o/oauth2/token",
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RefreshToken: cfg.RefreshToken,
+			Logger:       logger,
+		}), nil
+	}
+
+	data := []byte(cfg.ServiceAccountKey)
+	if len(data) == 0 {
+		data, err = os.ReadFile(filepath.Clean(cfg.ServiceAccountKeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("reading CHROME_SERVICE_ACCOUNT_KEY_FILE: %w", err)
+		}
+	}
+
+	key, err := chrome.ParseServiceAccountKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing chrome service account key: %w", err)
+	}
+
+	logger.Debug("using service account credentials", "client_email", key.ClientEmail)
+
+	return chrome.NewServiceAccountClient(chrome.ServiceAccountClientConfig{
+		Key:    key,
+		Logger: logger,
+	})
+}
+
+func getChromeV1Store(c *cli.Context) (*chrome.StoreV1, error) {
+	cfg, err := newChromeConfig(c)
 	if err != nil {
 		return nil, err
 	}
 
 	chromeLogger := slog.Default().With(slogutil.KeyPrefix, "chrome")
 
-	client := chrome.NewClient(chrome.ClientConfig{
-		URL:          "https://accounts.google.com/o/oauth2/token",
-		ClientID:     cfg.ClientID,
-		ClientSecret: cfg.ClientSecret,
-		RefreshToken: cfg.RefreshToken,
-		Logger:       chromeLogger,
-	})
+	client, err := newChromeAuthorizer(cfg, chromeLogger)
+	if err != nil {
+		return nil, err
+	}
+
+	return newChromeV1Store(client, chromeLogger), nil
+}
 
-	store := chrome.NewStoreV1(chrome.StoreV1Config{
+// newChromeV1Store returns the v1 API store using client.
+func newChromeV1Store(client chrome.Authorizer, logger *slog.Logger) (store *chrome.StoreV1) {
+	return chrome.NewStoreV1(chrome.StoreV1Config{
 		Client: client,
 		URL: &url.URL{
 			Scheme: "https",
 			Host:   "www.googleapis.com",
 		},
-		Logger: chromeLogger,
+		Logger: logger,
 	})
-
-	return store, nil
 }
 
-func getChromeV2Store() (*chrome.StoreV2, error) {
-	cfg, err := newChromeConfig()
-	if err != nil {
+// newChromeV2Store returns the v2 API store of the publisher from cfg using
+// client.
+func newChromeV2Store(
+	cfg *chromeConfig,
+	client chrome.Authorizer,
+	logger *slog.Logger,
+) (store *chrome.StoreV2, err error) {
+	if err = validate.NotEmpty("CHROME_PUBLISHER_ID", cfg.PublisherID); err != nil {
 		return nil, err
 	}
 
-	if err := validate.NotEmpty("CHROME_PUBLISHER_ID", cfg.PublisherID); err != nil {
-		return nil, err
-	}
-
-	chromeLogger := slog.Default().With(slogutil.KeyPrefix, "chrome")
-
-	client := chrome.NewClient(chrome.ClientConfig{
-		URL:          "https://accounts.google.com/o/oauth2/token",
-		ClientID:     cfg.ClientID,
-		ClientSecret: cfg.ClientSecret,
-		RefreshToken: cfg.RefreshToken,
-		Logger:       chromeLogger,
-	})
-
-	store := chrome.NewStoreV2(chrome.StoreV2Config{
+	return chrome.NewStoreV2(chrome.StoreV2Config{
 		Client: client,
 		URL: &url.URL{
 			Scheme: "https",
 			Host:   "chromewebstore.googleapis.com",
 		},
 		PublisherID: cfg.PublisherID,
-		Logger:      chromeLogger,
-	})
-
-	return store, nil
+		Logger:      logger,
+	}), nil
 }
 
 // chromeStore holds either a V1 or V2 store based on API version.
@@ -109,23 +225,32 @@ type chromeStore struct {
 }
 
 // getChromeStore returns a chrome store supporting the configured API version.
-func getChromeStore() (*chromeStore, error) {
-	cfg, err := newChromeConfig()
+func getChromeStore(c *cli.Context) (*chromeStore, error) {
+	cfg, err := newChromeConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	chromeLogger := slog.Default().With(slogutil.KeyPrefix, "chrome")
+
+	client, err := newChromeAuthorizer(cfg, chromeLogger)
 	if err != nil {
 		return nil, err
 	}
 
+	return newChromeStore(cfg, client, chromeLogger)
+}
+
+// newChromeStore returns a chrome store for the API version from cfg using
+// client.
+func newChromeStore(cfg *chromeConfig, client chrome.Authorizer, logger *slog.Logger) (*chromeStore, error) {
 	apiVersion := cfg.APIVersion
 
 	switch apiVersion {
 	case chromeAPIVersionV1:
-		store, err := getChromeV1Store()
-		if err != nil {
-			return nil, fmt.Errorf("initializing chrome store v1: %w", err)
-		}
-		return &chromeStore{v1: store, apiVersion: apiVersion}, nil
+		return &chromeStore{v1: newChromeV1Store(client, logger), apiVersion: apiVersion}, nil
 	case chromeAPIVersionV2:
-		store, err := getChromeV2Store()
+		store, err := newChromeV2Store(cfg, client, logger)
 		if err != nil {
 			return nil, fmt.Errorf("initializing chrome store v2: %w", err)
 		}
@@ -135,22 +260,33 @@ func getChromeStore() (*chromeStore, error) {
 	}
 }
 
-func getFirefoxStore() (*firefox.Store, error) {
+func getFirefoxStore(c *cli.Context) (*firefox.Store, error) {
 	const DefaultBaseURL = "addons.mozilla.org"
 
 	type config struct {
-		ClientID     string `env:"FIREFOX_CLIENT_ID,notEmpty"`
-		ClientSecret string `env:"FIREFOX_CLIENT_SECRET,notEmpty"`
+		ClientID     string `env:"FIREFOX_CLIENT_ID,notEmpty" secret:"true"`
+		ClientSecret string `env:"FIREFOX_CLIENT_SECRET,notEmpty" secret:"true"`
 		BaseURL      string `env:"FIREFOX_BASE_URL"`
+		// TokenExpiration is the lifetime of the JWT, at most 5 minutes.
+		TokenExpiration time.Duration `env:"FIREFOX_TOKEN_EXPIRATION"`
 	}
 
 	cfg := config{
 		BaseURL: DefaultBaseURL,
 	}
-	if err := env.Parse(&cfg); err != nil {
+	if err := parseCredentials(c, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse environment variables: %w", err)
 	}
 
+	if cfg.TokenExpiration > firefoxapi.MaxTokenExpiration {
+		return nil, fmt.Errorf("FIREFOX_TOKEN_EXPIRATION must not exceed %s", firefoxapi.MaxTokenExpiration)
+	}
+
+	err := firefoxapi.CheckCredentials(cfg.ClientID, cfg.ClientSecret)
+	if err != nil {
+		slog.Warn("firefox credentials look malformed", slogutil.KeyError, err)
+	}
+
 	firefoxAPI := firefoxapi.NewAPI(firefoxapi.Config{
 		ClientID:     cfg.ClientID,
 		ClientSecret: cfg.ClientSecret,
@@ -158,29 +294,108 @@ func getFirefoxStore() (*firefox.Store, error) {
 			Scheme: "https",
 			Host:   cfg.BaseURL,
 		},
-		Logger: slog.Default().With(slogutil.KeyPrefix, "firefox/api"),
+		Logger:          slog.Default().With(slogutil.KeyPrefix, "firefox/api"),
+		RequestTimeout:  c.Duration("request-timeout"),
+		TokenExpiration: cfg.TokenExpiration,
 	})
 
 	store := firefox.NewStore(firefox.StoreConfig{
-		API:    firefoxAPI,
-		Logger: slog.Default().With(slogutil.KeyPrefix, "firefox"),
+		API:            firefoxAPI,
+		Logger:         slog.Default().With(slogutil.KeyPrefix, "firefox"),
+		FailOnWarnings: c.Bool("fail-on-warnings"),
+		Poll: firefox.PollConfig{
+			Interval:          c.Duration("poll-interval"),
+			MaxInterval:       c.Duration("max-poll-interval"),
+			ValidationTimeout: c.Duration("validation-timeout"),
+			SigningTimeout:    c.Duration("signing-timeout"),
+		},
 	})
 
 	return store, nil
 }
 
-func getEdgeStore() (*edge.Store, error) {
+// edgeKeyExpiryConfig describes the expiry of the Edge v1.1 API key.
+type edgeKeyExpiryConfig struct {
+	// Issued and Expires are the issue and expiry dates of the API key in
+	// YYYY-MM-DD or RFC 3339 format.  Expires takes precedence.
+	Issued  string `env:"EDGE_API_KEY_ISSUED"`
+	Expires string `env:"EDGE_API_KEY_EXPIRES"`
+	// WarnDays is the number of days before the expiry when the warnings
+	// start.
+	WarnDays int `env:"EDGE_API_KEY_WARN_DAYS"`
+	// FailOnExpiry makes the commands fail instead of warning when the key
+	// expires within WarnDays or has expired.
+	FailOnExpiry bool `env:"EDGE_API_KEY_FAIL_ON_EXPIRY"`
+}
+
+// getEdgeKeyExpiry returns the expiry of the Edge API key, or nil if its dates
+// aren't set.
+func getEdgeKeyExpiry(c *cli.Context) (expiry *edge.APIKeyExpiry, failOnExpiry bool, err error) {
+	cfg := edgeKeyExpiryConfig{}
+	if err = parseCredentials(c, &cfg); err != nil {
+		return nil, false, fmt.Errorf("failed to parse environment variables: %w", err)
+	}
+
+	var issued, expires time.Time
+	if cfg.Issued != "" {
+		issued, err = edge.ParseKeyDate(cfg.Issued)
+		if err != nil {
+			return nil, false, fmt.Errorf("EDGE_API_KEY_ISSUED: %w", err)
+		}
+	}
+
+	if cfg.Expires != "" {
+		expires, err = edge.ParseKeyDate(cfg.Expires)
+		if err != nil {
+			return nil, false, fmt.Errorf("EDGE_API_KEY_EXPIRES: %w", err)
+		}
+	}
+
+	warnWindow := time.Duration(cfg.WarnDays) * 24 * time.Hour
+
+	return edge.NewAPIKeyExpiry(issued, expires, warnWindow), cfg.FailOnExpiry, nil
+}
+
+// checkEdgeKeyExpiry warns if the Edge API key expires soon or has expired.
+// It returns an error instead if EDGE_API_KEY_FAIL_ON_EXPIRY is set.
+func checkEdgeKeyExpiry(c *cli.Context) (err error) {
+	expiry, failOnExpiry, err := getEdgeKeyExpiry(c)
+	if err != nil {
+		return err
+	}
+
+	if expiry == nil {
+		slog.Debug("edge api key expiry is unknown, set EDGE_API_KEY_EXPIRES to track it")
+
+		return nil
+	}
+
+	_, err = expiry.Check(time.Now())
+	if err == nil {
+		return nil
+	}
+
+	if failOnExpiry {
+		return err
+	}
+
+	slog.Warn("rotate the edge api key", slogutil.KeyError, err)
+
+	return nil
+}
+
+func getEdgeStore(c *cli.Context) (*edge.Store, error) {
 	type config struct {
-		ClientID       string `env:"EDGE_CLIENT_ID,notEmpty"`
-		ClientSecret   string `env:"EDGE_CLIENT_SECRET"`
+		ClientID       string `env:"EDGE_CLIENT_ID,notEmpty" secret:"true"`
+		ClientSecret   string `env:"EDGE_CLIENT_SECRET" secret:"true"`
 		AccessTokenURL string `env:"EDGE_ACCESS_TOKEN_URL"`
-		APIKey         string `env:"EDGE_API_KEY"`
+		APIKey         string `env:"EDGE_API_KEY" secret:"true"`
 		APIVersion     string `env:"EDGE_API_VERSION" envDefault:"v1"`
 	}
 
 	cfg := config{}
 
-	if err := env.Parse(&cfg); err != nil {
+	if err := parseCredentials(c, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse environment variables: %w", err)
 	}
 
@@ -203,6 +418,9 @@ func getEdgeStore() (*edge.Store, error) {
 		if err := validate.NotEmpty("EDGE_API_KEY", cfg.APIKey); err != nil {
 			return nil, err
 		}
+		if err := checkEdgeKeyExpiry(c); err != nil {
+			return nil, err
+		}
 		clientConfig = edge.NewV1_1Config(cfg.ClientID, cfg.APIKey)
 	default:
 		return nil, fmt.Errorf("unsupported API version: %s", cfg.APIVersion)
@@ -222,13 +440,40 @@ func getEdgeStore() (*edge.Store, error) {
 	return store, nil
 }
 
+// printValidationReport prints the validation report to stdout if err is a
+// *firefox.ValidationError.  It returns err unchanged, so that it can be used
+// in the return statements of the actions.
+func printValidationReport(c *cli.Context, err error) error {
+	var validationErr *firefox.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Validation == nil {
+		return err
+	}
+
+	var printErr error
+	switch c.String("format") {
+	case formatJSON:
+		printErr = validationErr.Validation.WriteJSON(os.Stdout)
+	default:
+		printErr = validationErr.Validation.WriteText(os.Stdout)
+	}
+
+	if printErr != nil {
+		slog.Error("printing validation report", "error", printErr)
+	}
+
+	return err
+}
+
 func firefoxStatusAction(c *cli.Context) error {
-	store, err := getFirefoxStore()
+	store, err := getFirefoxStore(c)
 	if err != nil {
 		return fmt.Errorf("initializing firefox store: %w", err)
 	}
 
-	appID := c.String("app")
+	appID, err := getAppID(c, extstore.Firefox)
+	if err != nil {
+		return err
+	}
 
 	status, err := store.Status(appID)
 	if err != nil {
@@ -240,13 +485,80 @@ func firefoxStatusAction(c *cli.Context) error {
 	return nil
 }
 
+func chromeAuthAction(c *cli.Context) error {
+	type config struct {
+		ClientID     string `env:"CHROME_CLIENT_ID,notEmpty" secret:"true"`
+		ClientSecret string `env:"CHROME_CLIENT_SECRET,notEmpty" secret:"true"`
+	}
+
+	cfg := config{}
+	if err := parseCredentials(c, &cfg); err != nil {
+		return fmt.Errorf("failed to parse Chrome environment variables: %w", err)
+	}
+
+	refreshToken, err := chrome.RunLoopbackFlow(c.Context, chrome.LoopbackFlowConfig{
+		Logger: slog.Default().With(slogutil.KeyPrefix, "chrome"),
+		PrintURL: func(consentURL string) {
+			fmt.Printf("Open the following URL in the browser and grant access:\n\n%s\n\n", consentURL)
+		},
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		Timeout:      c.Duration("timeout"),
+	})
+	if err != nil {
+		return fmt.Errorf("authorizing: %w", err)
+	}
+
+	envFile := c.String("env-file")
+	if envFile == "" {
+		fmt.Printf("CHROME_REFRESH_TOKEN=%s\n", refreshToken)
+
+		return nil
+	}
+
+	err = fileutil.SetEnvFileValue(envFile, "CHROME_REFRESH_TOKEN", refreshToken)
+	if err != nil {
+		return fmt.Errorf("saving refresh token: %w", err)
+	}
+
+	fmt.Printf("CHROME_REFRESH_TOKEN saved to %s\n", envFile)
+
+	return nil
+}
+
+func firefoxWhoAmIAction(c *cli.Context) error {
+	store, err := getFirefoxStore(c)
+	if err != nil {
+		return fmt.Errorf("initializing firefox store: %w", err)
+	}
+
+	amoProfile, err := store.WhoAmI()
+	if err != nil {
+		return fmt.Errorf("verifying credentials: %w", err)
+	}
+
+	if c.String("format") == formatJSON {
+		return json.NewEncoder(os.Stdout).Encode(amoProfile)
+	}
+
+	fmt.Printf("Authenticated as %s (id %d)\n", amoProfile.Username, amoProfile.ID)
+	if amoProfile.Email != "" {
+		fmt.Printf("Email: %s\n", amoProfile.Email)
+	}
+
+	return nil
+}
+
 func chromeStatusAction(c *cli.Context) error {
-	store, err := getChromeStore()
+	store, err := getChromeStore(c)
 	if err != nil {
 		return err
 	}
 
-	appID := c.String("app")
+	appID, err := getAppID(c, extstore.Chrome)
+	if err != nil {
+		return err
+	}
 
 	switch store.apiVersion {
 	case chromeAPIVersionV1:
@@ -279,131 +591,505 @@ func chromeStatusAction(c *cli.Context) error {
 		}
 	}
 
-	return nil
+	return nil
+}
+
+func chromeInsertAction(c *cli.Context) error {
+	store, err := getChromeV1Store(c)
+	if err != nil {
+		return fmt.Errorf("initializing chrome store: %w", err)
+	}
+
+	filepath := c.String("file")
+	_, err = readPackageManifest(extstore.Chrome, filepath)
+	if err != nil {
+		return err
+	}
+
+	result, err := store.Insert(filepath)
+	if err != nil {
+		return fmt.Errorf("inserting extension: %w", err)
+	}
+
+	fmt.Println("Insert completed")
+	fmt.Printf("Item ID: %s\n", result.ID)
+	fmt.Printf("Upload State: %s\n", result.UploadStateV1)
+
+	return nil
+}
+
+func chromeUpdateAction(c *cli.Context) error {
+	store, err := getChromeStore(c)
+	if err != nil {
+		return err
+	}
+
+	filepath := c.String("file")
+	appID, err := getAppID(c, extstore.Chrome)
+	if err != nil {
+		return err
+	}
+
+	m, err := readPackageManifest(extstore.Chrome, filepath)
+	if err != nil {
+		return err
+	}
+
+	err = checkVersionIncrease(c, extstore.Chrome, appID, m, chromeStoreVersion(store, appID))
+	if err != nil {
+		return err
+	}
+
+	switch store.apiVersion {
+	case chromeAPIVersionV1:
+		result, err := store.v1.Update(appID, filepath)
+		if err != nil {
+			return fmt.Errorf("updating extension: %w", err)
+		}
+
+		fmt.Println("Update completed")
+		fmt.Printf("Item ID: %s\n", result.ID)
+		fmt.Printf("Upload State: %s\n", result.UploadStateV1)
+	case chromeAPIVersionV2:
+		result, err := store.v2.Upload(appID, filepath)
+		if err != nil {
+			return fmt.Errorf("uploading extension: %w", err)
+		}
+
+		fmt.Println("Upload completed")
+		fmt.Printf("Item ID: %s\n", result.ItemID)
+		fmt.Printf("Version: %s\n", result.CrxVersion)
+		fmt.Printf("Upload State: %s\n", result.UploadStateV2)
+	}
+
+	return nil
+}
+
+// edgeKeyStatus describes the expiry of the Edge API key in the status
+// output.
+type edgeKeyStatus struct {
+	ExpiresAt *time.Time `json:"api_key_expires_at,omitempty"`
+	Warning   string     `json:"warning,omitempty"`
+	DaysLeft  int        `json:"api_key_days_left,omitempty"`
+}
+
+func edgeStatusAction(c *cli.Context) error {
+	expiry, _, err := getEdgeKeyExpiry(c)
+	if err != nil {
+		return err
+	}
+
+	status := edgeKeyStatus{}
+	if expiry != nil {
+		left, checkErr := expiry.Check(time.Now())
+		status.ExpiresAt = &expiry.ExpiresAt
+		status.DaysLeft = edge.DaysLeft(left)
+		if checkErr != nil {
+			status.Warning = checkErr.Error()
+		}
+	}
+
+	if c.String("format") == formatJSON {
+		return json.NewEncoder(os.Stdout).Encode(status)
+	}
+
+	if expiry == nil {
+		fmt.Println("API key expiry: unknown, set EDGE_API_KEY_ISSUED or EDGE_API_KEY_EXPIRES")
+
+		return nil
+	}
+
+	fmt.Printf("API key expires: %s (%d days left)\n", expiry.ExpiresAt.Format(time.DateOnly), status.DaysLeft)
+	if status.Warning != "" {
+		fmt.Printf("Warning: %s\n", status.Warning)
+	}
+
+	return nil
+}
+
+func edgeInsertAction(c *cli.Context) error {
+	store, err := getEdgeStore(c)
+	if err != nil {
+		return fmt.Errorf("initializing edge store: %w", err)
+	}
+
+	result, err := store.Insert()
+	if err != nil {
+		return fmt.Errorf("inserting extension: %w", err)
+	}
+
+	fmt.Println(string(result))
+
+	return nil
+}
+
+// firefoxSourcePath returns the path of the source archive to attach to the
+// version: either the --source archive or the one built from --source-dir in a
+// temporary directory, which is removed by cleanup.  The path is empty if
+// neither is set.
+func firefoxSourcePath(c *cli.Context) (sourcepath string, cleanup func(), err error) {
+	cleanup = func() {}
+
+	sourceDir := c.String("source-dir")
+	if sourceDir == "" {
+		return c.String("source"), cleanup, nil
+	}
+
+	if c.String("source") != "" {
+		return "", cleanup, errors.Error("--source and --source-dir can't be used together")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "webext-source-*")
+	if err != nil {
+		return "", cleanup, fmt.Errorf("creating temporary directory: %w", err)
+	}
+
+	cleanup = func() {
+		if rmErr := os.RemoveAll(tmpDir); rmErr != nil {
+			slog.Warn("removing temporary source archive", slogutil.KeyError, rmErr)
+		}
+	}
+
+	sourcepath = filepath.Join(tmpDir, firefoxapi.DefaultSourceFilename)
+	err = firefox.BuildSourceArchive(firefox.SourceArchiveConfig{
+		Logger:  slog.Default().With(slogutil.KeyPrefix, "firefox"),
+		Dir:     sourceDir,
+		Readme:  c.String("source-readme"),
+		Include: c.StringSlice("source-include"),
+		Exclude: c.StringSlice("source-exclude"),
+	}, sourcepath)
+	if err != nil {
+		cleanup()
+
+		return "", func() {}, fmt.Errorf("building source archive: %w", err)
+	}
+
+	return sourcepath, cleanup, nil
+}
+
+func firefoxInsertAction(c *cli.Context) error {
+	store, err := getFirefoxStore(c)
+	if err != nil {
+		return fmt.Errorf("initializing firefox store: %w", err)
+	}
+
+	filepath := c.String("file")
+	_, err = readPackageManifest(extstore.Firefox, filepath)
+	if err != nil {
+		return err
+	}
+
+	sourcepath, cleanup, err := firefoxSourcePath(c)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	err = store.Insert(filepath, sourcepath)
+	if err != nil {
+		return fmt.Errorf("inserting extension: %w", printValidationReport(c, err))
+	}
+
+	fmt.Println("extension inserted")
+
+	return nil
+}
+
+func firefoxUpdateAction(c *cli.Context) error {
+	store, err := getFirefoxStore(c)
+	if err != nil {
+		return fmt.Errorf("getting firefox store: %w", err)
+	}
+
+	filepath := c.String("file")
+	m, err := readPackageManifest(extstore.Firefox, filepath)
+	if err != nil {
+		return err
+	}
+
+	err = checkVersionIncrease(c, extstore.Firefox, m.GeckoID(), m, firefoxStoreVersion(store, m.GeckoID()))
+	if err != nil {
+		return err
+	}
+
+	sourcepath, cleanup, err := firefoxSourcePath(c)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	channel, err := firefox.NewChannel(c.String("channel"))
+	if err != nil {
+		return fmt.Errorf("parsing channel: %w", err)
+	}
+
+	approvalNotes := c.String("approval-notes")
+
+	err = store.Update(filepath, sourcepath, channel, approvalNotes)
+	if err != nil {
+		return fmt.Errorf("updating extension: %w", printValidationReport(c, err))
+	}
+
+	fmt.Println("extension updated")
+
+	return nil
+}
+
+func firefoxValidateAction(c *cli.Context) error {
+	store, err := getFirefoxStore(c)
+	if err != nil {
+		return fmt.Errorf("getting firefox store: %w", err)
+	}
+
+	filepath := c.String("file")
+	channel, err := firefox.NewChannel(c.String("channel"))
+	if err != nil {
+		return fmt.Errorf("parsing channel: %w", err)
+	}
+
+	validation, err := store.Validate(filepath, channel)
+	if err != nil {
+		return fmt.Errorf("validating extension: %w", printValidationReport(c, err))
+	}
+
+	switch c.String("format") {
+	case formatJSON:
+		return validation.WriteJSON(os.Stdout)
+	default:
+		return validation.WriteText(os.Stdout)
+	}
+}
+
+// versionEntry is the entry of the versions list printed by the versions
+// command.
+type versionEntry struct {
+	ID            int       `json:"id"`
+	Version       string    `json:"version"`
+	Channel       string    `json:"channel"`
+	Status        string    `json:"status"`
+	Disabled      bool      `json:"disabled"`
+	Compatibility string    `json:"compatibility"`
+	Created       time.Time `json:"created"`
+}
+
+// newVersionEntry converts the version info into the versions list entry.
+func newVersionEntry(v *firefox.VersionInfo) (e versionEntry) {
+	compat := v.Compatibility.Firefox
+	compatibility := ""
+	if compat.Min != "" || compat.Max != "" {
+		compatibility = compat.Min + " - " + compat.Max
+	}
+
+	return versionEntry{
+		ID:            v.ID,
+		Version:       v.Version,
+		Channel:       v.Channel,
+		Status:        v.File.Status,
+		Disabled:      v.IsDisabled,
+		Compatibility: compatibility,
+		Created:       v.File.Created,
+	}
+}
+
+func firefoxVersionsAction(c *cli.Context) error {
+	store, err := getFirefoxStore(c)
+	if err != nil {
+		return fmt.Errorf("getting firefox store: %w", err)
+	}
+
+	appID, err := getAppID(c, extstore.Firefox)
+	if err != nil {
+		return err
+	}
+
+	filter := firefox.VersionFilter{
+		Status: c.String("status"),
+	}
+
+	if c.IsSet("channel") {
+		filter.Channel, err = firefox.NewChannel(c.String("channel"))
+		if err != nil {
+			return fmt.Errorf("parsing channel: %w", err)
+		}
+	}
+
+	versions, err := store.Versions(appID, filter)
+	if err != nil {
+		return fmt.Errorf("getting versions: %w", err)
+	}
+
+	entries := make([]versionEntry, 0, len(versions))
+	for _, v := range versions {
+		entries = append(entries, newVersionEntry(v))
+	}
+
+	if c.String("format") == formatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "    ")
+
+		return enc.Encode(entries)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tVERSION\tCHANNEL\tSTATUS\tDISABLED\tCOMPATIBILITY\tCREATED")
+	for _, e := range entries {
+		created := ""
+		if !e.Created.IsZero() {
+			created = e.Created.Format(time.DateTime)
+		}
+
+		_, _ = fmt.Fprintf(
+			w,
+			"%d\t%s\t%s\t%s\t%t\t%s\t%s\n",
+			e.ID,
+			e.Version,
+			e.Channel,
+			e.Status,
+			e.Disabled,
+			e.Compatibility,
+			created,
+		)
+	}
+
+	return w.Flush()
 }
 
-func chromeInsertAction(c *cli.Context) error {
-	store, err := getChromeV1Store()
+func firefoxDownloadAction(c *cli.Context) error {
+	store, err := getFirefoxStore(c)
 	if err != nil {
-		return fmt.Errorf("initializing chrome store: %w", err)
+		return fmt.Errorf("getting firefox store: %w", err)
 	}
 
-	filepath := c.String("file")
-
-	result, err := store.Insert(filepath)
+	appID, err := getAppID(c, extstore.Firefox)
 	if err != nil {
-		return fmt.Errorf("inserting extension: %w", err)
+		return err
 	}
 
-	fmt.Println("Insert completed")
-	fmt.Printf("Item ID: %s\n", result.ID)
-	fmt.Printf("Upload State: %s\n", result.UploadStateV1)
-
-	return nil
-}
+	version := c.String("version")
+	all := c.Bool("all")
 
-func chromeUpdateAction(c *cli.Context) error {
-	store, err := getChromeStore()
-	if err != nil {
-		return err
+	if all == (version != "") {
+		return errors.Error("exactly one of --version or --all must be specified")
 	}
 
-	filepath := c.String("file")
-	appID := c.String("app")
+	if all {
+		outputDir := cmp.Or(c.String("output"), ".")
 
-	switch store.apiVersion {
-	case chromeAPIVersionV1:
-		result, err := store.v1.Update(appID, filepath)
+		files, err := store.DownloadAll(appID, outputDir)
 		if err != nil {
-			return fmt.Errorf("updating extension: %w", err)
+			return fmt.Errorf("downloading signed versions: %w", err)
 		}
 
-		fmt.Println("Update completed")
-		fmt.Printf("Item ID: %s\n", result.ID)
-		fmt.Printf("Upload State: %s\n", result.UploadStateV1)
-	case chromeAPIVersionV2:
-		result, err := store.v2.Upload(appID, filepath)
-		if err != nil {
-			return fmt.Errorf("uploading extension: %w", err)
+		for _, f := range files {
+			fmt.Printf("Signed file saved to %s\n", f)
 		}
 
-		fmt.Println("Upload completed")
-		fmt.Printf("Item ID: %s\n", result.ItemID)
-		fmt.Printf("Version: %s\n", result.CrxVersion)
-		fmt.Printf("Upload State: %s\n", result.UploadStateV2)
+		return nil
+	}
+
+	// Name the file the same way as DownloadAll does.
+	output := cmp.Or(c.String("output"), "firefox-"+version+".xpi")
+
+	err = store.Download(appID, version, output)
+	if err != nil {
+		return fmt.Errorf("downloading signed version: %w", err)
 	}
 
+	fmt.Printf("Signed file saved to %s\n", output)
+
 	return nil
 }
 
-func edgeInsertAction(_ *cli.Context) error {
-	store, err := getEdgeStore()
+func firefoxDisableAction(c *cli.Context) error {
+	store, err := getFirefoxStore(c)
 	if err != nil {
-		return fmt.Errorf("initializing edge store: %w", err)
+		return fmt.Errorf("getting firefox store: %w", err)
 	}
 
-	result, err := store.Insert()
+	appID, err := getAppID(c, extstore.Firefox)
 	if err != nil {
-		return fmt.Errorf("inserting extension: %w", err)
+		return err
 	}
 
-	fmt.Println(string(result))
+	version := c.String("version")
+
+	err = store.DisableVersion(appID, version)
+	if err != nil {
+		return fmt.Errorf("disabling version: %w", err)
+	}
+
+	fmt.Printf("Version %s disabled\n", version)
 
 	return nil
 }
 
-func firefoxInsertAction(c *cli.Context) error {
-	store, err := getFirefoxStore()
+func firefoxEnableAction(c *cli.Context) error {
+	store, err := getFirefoxStore(c)
 	if err != nil {
-		return fmt.Errorf("initializing firefox store: %w", err)
+		return fmt.Errorf("getting firefox store: %w", err)
 	}
 
-	filepath := c.String("file")
-	sourcepath := c.String("source")
+	appID, err := getAppID(c, extstore.Firefox)
+	if err != nil {
+		return err
+	}
 
-	err = store.Insert(filepath, sourcepath)
+	version := c.String("version")
+
+	err = store.EnableVersion(appID, version)
 	if err != nil {
-		return fmt.Errorf("inserting extension: %w", err)
+		return fmt.Errorf("enabling version: %w", err)
 	}
 
-	fmt.Println("extension inserted")
+	fmt.Printf("Version %s enabled\n", version)
 
 	return nil
 }
 
-func firefoxUpdateAction(c *cli.Context) error {
-	store, err := getFirefoxStore()
+func firefoxDeleteAction(c *cli.Context) error {
+	store, err := getFirefoxStore(c)
 	if err != nil {
 		return fmt.Errorf("getting firefox store: %w", err)
 	}
 
-	filepath := c.String("file")
-	sourcepath := c.String("source")
-	channel, err := firefox.NewChannel(c.String("channel"))
+	appID, err := getAppID(c, extstore.Firefox)
 	if err != nil {
-		return fmt.Errorf("parsing channel: %w", err)
+		return err
 	}
 
-	approvalNotes := c.String("approval-notes")
+	version := c.String("version")
 
-	err = store.Update(filepath, sourcepath, channel, approvalNotes)
+	err = store.DeleteVersion(appID, version)
 	if err != nil {
-		return fmt.Errorf("updating extension: %w", err)
+		return fmt.Errorf("deleting version: %w", err)
 	}
 
-	fmt.Println("extension updated")
+	fmt.Printf("Version %s deleted\n", version)
 
 	return nil
 }
 
 func edgeUpdateAction(c *cli.Context) error {
-	store, err := getEdgeStore()
+	store, err := getEdgeStore(c)
 	if err != nil {
 		return fmt.Errorf("getting edge store: %w", err)
 	}
 
 	filepath := c.String("file")
-	appID := c.String("app")
+	appID, err := getAppID(c, extstore.Edge)
+	if err != nil {
+		return err
+	}
+
+	m, err := readPackageManifest(extstore.Edge, filepath)
+	if err != nil {
+		return err
+	}
+
+	err = checkVersionIncrease(c, extstore.Edge, appID, m, edgeStoreVersion)
+	if err != nil {
+		return err
+	}
+
 	timeout := c.Int("timeout")
 
 	result, err := store.Update(appID, filepath, edge.UpdateOptions{
@@ -419,12 +1105,15 @@ func edgeUpdateAction(c *cli.Context) error {
 }
 
 func chromePublishAction(c *cli.Context) error {
-	store, err := getChromeStore()
+	store, err := getChromeStore(c)
 	if err != nil {
 		return err
 	}
 
-	appID := c.String("app")
+	appID, err := getAppID(c, extstore.Chrome)
+	if err != nil {
+		return err
+	}
 
 	switch store.apiVersion {
 	case chromeAPIVersionV1:
@@ -482,12 +1171,15 @@ func chromePublishAction(c *cli.Context) error {
 }
 
 func edgePublishAction(c *cli.Context) error {
-	store, err := getEdgeStore()
+	store, err := getEdgeStore(c)
 	if err != nil {
 		return fmt.Errorf("getting edge store: %w", err)
 	}
 
-	appID := c.String("app")
+	appID, err := getAppID(c, extstore.Edge)
+	if err != nil {
+		return err
+	}
 
 	result, err := store.Publish(appID)
 	if err != nil {
@@ -500,25 +1192,68 @@ func edgePublishAction(c *cli.Context) error {
 }
 
 func firefoxSignAction(c *cli.Context) error {
-	store, err := getFirefoxStore()
+	if c.Bool("no-wait") && c.IsSet("update-manifest") {
+		return errors.Error(
+			"--no-wait and --update-manifest can't be used together, since the update manifest " +
+				"needs the signed package; run update-manifest after downloading it",
+		)
+	}
+
+	store, err := getFirefoxStore(c)
 	if err != nil {
 		return fmt.Errorf("getting firefox store: %w", err)
 	}
 
 	filepath := c.String("file")
-	sourcepath := c.String("source")
+	sourcepath, cleanup, err := firefoxSourcePath(c)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
 	output := c.String("output")
 	approvalNotes := c.String("approval-notes")
 
-	err = store.Sign(filepath, sourcepath, output, approvalNotes)
-	if err != nil {
-		return fmt.Errorf("signing extension: %w", err)
+	err = store.Sign(filepath, sourcepath, output, approvalNotes, firefox.SignOptions{
+		NoWait: c.Bool("no-wait"),
+	})
+	if errors.Is(err, firefox.ErrSigningPending) {
+		fmt.Println("Version is pending signature, run the command again to download it once signed")
+
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("signing extension: %w", printValidationReport(c, err))
 	}
 
 	fmt.Printf("Signed file saved to %s\n", output)
+
+	if !c.IsSet("update-manifest") {
+		return nil
+	}
+
+	return addToUpdateManifest(c.String("update-manifest"), output, c.String("update-link"))
+}
+
+// addToUpdateManifest adds the signed package at xpiPath to the update
+// manifest and prints the result.
+func addToUpdateManifest(manifestPath, xpiPath, updateLink string) error {
+	if updateLink == "" {
+		return errors.Error("--update-link is required to update the update manifest")
+	}
+
+	entry, err := firefox.AddToUpdateManifest(manifestPath, xpiPath, updateLink)
+	if err != nil {
+		return fmt.Errorf("updating update manifest: %w", err)
+	}
+
+	fmt.Printf("Version %s added to %s with update link %s\n", entry.Version, manifestPath, entry.UpdateLink)
+
 	return nil
 }
 
+func firefoxUpdateManifestAction(c *cli.Context) error {
+	return addToUpdateManifest(c.String("update-manifest"), c.String("file"), c.String("update-link"))
+}
+
 // Main is the entry point for the command-line application.
 func Main() {
 	// we don't care if method fails on reading .env file, we will try to read config from environment
@@ -542,13 +1277,71 @@ func Main() {
 			})
 			slog.SetDefault(handler)
 
+			p, err := loadProfile(ctx)
+			if err != nil {
+				return fmt.Errorf("loading profile: %w", err)
+			}
+
+			var profileEnv map[string]string
+			if p != nil {
+				profileEnv = p.Env
+				if profileEnv == nil {
+					profileEnv = map[string]string{}
+				}
+			}
+
+			resolver, err := credentials.NewResolver(credentials.Config{
+				Logger:                handler.With(slogutil.KeyPrefix, "credentials"),
+				SecretsFile:           ctx.String("secrets-file"),
+				SecretsCommand:        ctx.String("secrets-command"),
+				SecretsCommandTimeout: ctx.Duration("secrets-command-timeout"),
+				Profile:               profileEnv,
+				ProfileName:           ctx.String("profile"),
+			})
+			if err != nil {
+				return fmt.Errorf("initializing credentials: %w", err)
+			}
+
+			ctx.App.Metadata = map[string]any{
+				credentialsMetadataKey: resolver,
+				profileMetadataKey:     p,
+			}
+
 			return nil
 		},
 	}
 
-	appFlag := &cli.StringFlag{Name: "app", Aliases: []string{"a"}, Required: true}
+	appFlag := &cli.StringFlag{
+		Name:    "app",
+		Aliases: []string{"a"},
+		Usage:   "item id in the store, required unless set by the profile",
+	}
 	fileFlag := &cli.StringFlag{Name: "file", Aliases: []string{"f"}, Required: true}
 	sourceFlag := &cli.StringFlag{Name: "source", Aliases: []string{"s"}}
+	// sourceDirFlags build the source archive from a directory instead of
+	// using the --source archive.
+	sourceDirFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:     "source-dir",
+			Usage:    "directory to build the source archive from, respecting .gitignore",
+			Category: "Source code:",
+		},
+		&cli.StringSliceFlag{
+			Name:     "source-include",
+			Usage:    "only include files matching the .gitignore-style pattern in the source archive",
+			Category: "Source code:",
+		},
+		&cli.StringSliceFlag{
+			Name:     "source-exclude",
+			Usage:    "exclude files matching the .gitignore-style pattern from the source archive",
+			Category: "Source code:",
+		},
+		&cli.StringFlag{
+			Name:     "source-readme",
+			Usage:    "file with build instructions for reviewers added to the root of the source archive",
+			Category: "Source code:",
+		},
+	}
 	timeoutFlag := &cli.IntFlag{
 		Name:        "timeout",
 		Aliases:     []string{"t"},
@@ -568,7 +1361,158 @@ func Main() {
 		Usage:   "information for Mozilla reviewers, visible only to Mozilla",
 	}
 
-	app.Flags = []cli.Flag{verboseFlag}
+	formatFlag := &cli.StringFlag{
+		Name:  "format",
+		Usage: fmt.Sprintf("output format (%s or %s)", formatText, formatJSON),
+		Value: formatText,
+	}
+	versionFlag := &cli.StringFlag{
+		Name:     "version",
+		Usage:    "version of the extension, e.g. 1.2.3",
+		Required: true,
+	}
+	updateManifestFlag := &cli.StringFlag{
+		Name:    "update-manifest",
+		Aliases: []string{"m"},
+		Usage:   "path to the self-hosted update manifest (updates.json) to create or update",
+	}
+	updateLinkFlag := &cli.StringFlag{
+		Name: "update-link",
+		Usage: fmt.Sprintf(
+			"URL of the signed package for the update manifest, %s is replaced with the extension version",
+			firefox.UpdateLinkVersionPlaceholder,
+		),
+	}
+	failOnWarningsFlag := &cli.BoolFlag{
+		Name:  "fail-on-warnings",
+		Usage: "treat AMO validation warnings as errors",
+	}
+
+	requestTimeoutFlag := &cli.DurationFlag{
+		Name:     "request-timeout",
+		Usage:    "timeout of a single request to the store API",
+		EnvVars:  []string{"FIREFOX_REQUEST_TIMEOUT"},
+		Value:    firefoxapi.DefaultRequestTimeout,
+		Category: "Timeouts:",
+	}
+	// firefoxPollFlags configure waiting for AMO to validate and sign the
+	// extension.
+	firefoxPollFlags := []cli.Flag{
+		&cli.DurationFlag{
+			Name:     "poll-interval",
+			Usage:    "initial interval between status requests, doubled while the status is pending",
+			EnvVars:  []string{"FIREFOX_POLL_INTERVAL"},
+			Value:    firefox.DefaultPollInterval,
+			Category: "Timeouts:",
+		},
+		&cli.DurationFlag{
+			Name:     "max-poll-interval",
+			Usage:    "maximum interval between status requests",
+			EnvVars:  []string{"FIREFOX_MAX_POLL_INTERVAL"},
+			Value:    firefox.DefaultMaxPollInterval,
+			Category: "Timeouts:",
+		},
+		&cli.DurationFlag{
+			Name:     "validation-timeout",
+			Usage:    "maximum time to wait for the upload validation",
+			EnvVars:  []string{"FIREFOX_VALIDATION_TIMEOUT"},
+			Value:    firefox.DefaultValidationTimeout,
+			Category: "Timeouts:",
+		},
+		&cli.DurationFlag{
+			Name:     "signing-timeout",
+			Usage:    "maximum time to wait for the version signing",
+			EnvVars:  []string{"FIREFOX_SIGNING_TIMEOUT"},
+			Value:    firefox.DefaultSigningTimeout,
+			Category: "Timeouts:",
+		},
+		requestTimeoutFlag,
+	}
+
+	app.Flags = []cli.Flag{
+		verboseFlag,
+		&cli.StringFlag{
+			Name:     "secrets-file",
+			Usage:    "JSON or YAML file with credentials used when they aren't set in the environment",
+			EnvVars:  []string{"GO_WEBEXT_SECRETS_FILE"},
+			Category: "Credentials:",
+		},
+		&cli.StringFlag{
+			Name: "secrets-command",
+			Usage: "shell command printing the credential named by $" + credentials.CommandKeyEnv +
+				", used when it isn't set in the environment or the secrets file",
+			EnvVars:  []string{"GO_WEBEXT_SECRETS_COMMAND"},
+			Category: "Credentials:",
+		},
+		&cli.DurationFlag{
+			Name:     "secrets-command-timeout",
+			Usage:    "timeout of a single run of the secrets command",
+			Value:    credentials.DefaultCommandTimeout,
+			EnvVars:  []string{"GO_WEBEXT_SECRETS_COMMAND_TIMEOUT"},
+			Category: "Credentials:",
+		},
+		&cli.StringFlag{
+			Name:     "profile",
+			Aliases:  []string{"P"},
+			Usage:    "named profile from the config file selecting credentials and item ids",
+			EnvVars:  []string{"GO_WEBEXT_PROFILE"},
+			Category: "Credentials:",
+		},
+		&cli.StringFlag{
+			Name:     "config",
+			Usage:    "config file with the profiles",
+			EnvVars:  []string{"GO_WEBEXT_CONFIG"},
+			Value:    profile.DefaultConfigFile,
+			Category: "Credentials:",
+		},
+	}
+
+	forceFlag := &cli.BoolFlag{
+		Name:  "force",
+		Usage: "upload even if the package version isn't greater than the store version",
+	}
+
+	lintFlags := []cli.Flag{
+		fileFlag,
+		&cli.StringFlag{
+			Name:    "app",
+			Aliases: []string{"a"},
+			Usage:   "item id to compare the package version with the store version",
+		},
+		&cli.BoolFlag{
+			Name:  "offline",
+			Usage: "don't compare the package version with the store version",
+		},
+		&cli.Int64Flag{
+			Name:        "max-size",
+			Usage:       "maximum package size in bytes",
+			DefaultText: "limit of the store",
+		},
+		formatFlag,
+	}
+
+	packFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:     "dir",
+			Aliases:  []string{"d"},
+			Usage:    "directory with the extension, manifest.json must be in its root",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:        "output",
+			Aliases:     []string{"o"},
+			Usage:       "output zip file",
+			DefaultText: "<store>.zip",
+		},
+		&cli.StringSliceFlag{
+			Name:  "overlay",
+			Usage: "JSON merge patch applied to manifest.json, can be repeated",
+		},
+		&cli.StringSliceFlag{
+			Name:  "exclude",
+			Usage: "don't add files matching the .gitignore-style pattern",
+		},
+	}
 
 	app.Commands = []*cli.Command{{
 		Name:  "status",
@@ -583,6 +1527,107 @@ func Main() {
 			Usage:  "Chrome Store",
 			Action: chromeStatusAction,
 			Flags:  []cli.Flag{appFlag},
+		}, {
+			Name:   "edge",
+			Usage:  "Edge Store, prints the API key expiry as Edge has no status API",
+			Action: edgeStatusAction,
+			Flags:  []cli.Flag{formatFlag},
+		}},
+	}, {
+		Name:  "auth",
+		Usage: "obtains store credentials interactively",
+		Subcommands: []*cli.Command{{
+			Name:  "chrome",
+			Usage: "obtains CHROME_REFRESH_TOKEN via the browser consent and saves it to the env file",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "env-file",
+					Usage: "env file to save the refresh token to, print it if empty",
+					Value: ".env",
+				},
+				&cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "time to wait for the consent",
+					Value: chrome.DefaultLoopbackTimeout,
+				},
+			},
+			Action: chromeAuthAction,
+		}},
+	}, {
+		Name:  "lint",
+		Usage: "checks the package against the rules of the store before uploading",
+		Subcommands: []*cli.Command{{
+			Name:   "chrome",
+			Usage:  "checks the package for the Chrome Web Store",
+			Action: lintAction(extstore.Chrome),
+			Flags:  lintFlags,
+		}, {
+			Name:   "firefox",
+			Usage:  "checks the package for AMO",
+			Action: lintAction(extstore.Firefox),
+			Flags:  lintFlags,
+		}, {
+			Name:   "edge",
+			Usage:  "checks the package for Edge Add-ons",
+			Action: lintAction(extstore.Edge),
+			Flags:  lintFlags,
+		}},
+	}, {
+		Name:  "pack",
+		Usage: "builds the package for the store from a directory",
+		Subcommands: []*cli.Command{{
+			Name:   "chrome",
+			Usage:  "builds the package for the Chrome Web Store",
+			Action: packAction(extstore.Chrome),
+			Flags:  packFlags,
+		}, {
+			Name:   "firefox",
+			Usage:  "builds the package for AMO",
+			Action: packAction(extstore.Firefox),
+			Flags:  packFlags,
+		}, {
+			Name:   "edge",
+			Usage:  "builds the package for Edge Add-ons",
+			Action: packAction(extstore.Edge),
+			Flags:  packFlags,
+		}},
+	}, {
+		Name:      "diff",
+		Usage:     "compares two versions of a package: files, manifest fields and permissions",
+		ArgsUsage: "<old.zip|xpi|crx> <new.zip|xpi|crx>",
+		Action:    diffAction,
+		Flags: []cli.Flag{
+			formatFlag,
+			&cli.BoolFlag{
+				Name:  "fail-on-new-permissions",
+				Usage: "exit with an error if the new package requests new required permissions",
+			},
+		},
+	}, {
+		Name:      "verify-reproducible",
+		Usage:     "compares two archives entry by entry and fails if they differ",
+		ArgsUsage: "<first.zip> <second.zip>",
+		Action:    verifyReproducibleAction,
+		Flags:     []cli.Flag{formatFlag},
+	}, {
+		Name:   "doctor",
+		Usage:  "checks credentials and connectivity of the configured stores",
+		Action: doctorAction,
+		Flags: []cli.Flag{
+			formatFlag,
+			requestTimeoutFlag,
+			&cli.StringFlag{Name: "chrome-app", Usage: "chrome item id to read the status of"},
+			&cli.StringFlag{Name: "firefox-app", Usage: "firefox add-on id to read the status of"},
+			&cli.StringFlag{Name: "edge-app", Usage: "edge product id to check the access to"},
+		},
+	}, {
+		Name:  "whoami",
+		Usage: "verifies credentials and prints the account they belong to",
+		Subcommands: []*cli.Command{{
+			Name:   "firefox",
+			Usage:  "verifies AMO API credentials",
+			Action: firefoxWhoAmIAction,
+			Flags:  []cli.Flag{formatFlag, requestTimeoutFlag},
 		}},
 	}, {
 		Name:  "insert",
@@ -599,10 +1644,12 @@ func Main() {
 		}, {
 			Name:  "firefox",
 			Usage: "inserts new extension to the firefox store",
-			Flags: []cli.Flag{
+			Flags: slices.Concat([]cli.Flag{
 				fileFlag,
 				sourceFlag,
-			},
+				failOnWarningsFlag,
+				formatFlag,
+			}, sourceDirFlags, firefoxPollFlags),
 			Action: firefoxInsertAction,
 		}},
 	}, {
@@ -612,6 +1659,7 @@ func Main() {
 			Name:  "chrome",
 			Usage: "updates version of extension in the chrome store",
 			Flags: []cli.Flag{
+				forceFlag,
 				appFlag,
 				fileFlag,
 			},
@@ -619,23 +1667,46 @@ func Main() {
 		}, {
 			Name:  "firefox",
 			Usage: "updates version of extension in the firefox store",
-			Flags: []cli.Flag{
+			Flags: slices.Concat([]cli.Flag{
+				forceFlag,
 				fileFlag,
 				sourceFlag,
 				channelFlag,
 				approvalNotesFlag,
-			},
+				failOnWarningsFlag,
+				formatFlag,
+			}, sourceDirFlags, firefoxPollFlags),
 			Action: firefoxUpdateAction,
 		}, {
 			Name:  "edge",
 			Usage: "updates version of extension in the edge store",
 			Flags: []cli.Flag{
+				forceFlag,
 				fileFlag,
 				appFlag,
 				timeoutFlag,
 			},
 			Action: edgeUpdateAction,
 		}},
+	}, {
+		Name:  "validate",
+		Usage: "validates extension in the store without creating a new version",
+		Subcommands: []*cli.Command{{
+			Name:  "firefox",
+			Usage: "uploads extension to the firefox store and prints the validation report",
+			Flags: append([]cli.Flag{
+				fileFlag,
+				&cli.StringFlag{
+					Name:    "channel",
+					Aliases: []string{"c"},
+					Usage:   "channel to validate against (listed or unlisted)",
+					Value:   string(firefox.ChannelListed),
+				},
+				failOnWarningsFlag,
+				formatFlag,
+			}, firefoxPollFlags...),
+			Action: firefoxValidateAction,
+		}},
 	}, {
 		Name:  "publish",
 		Usage: "publishes extension to the store",
@@ -674,13 +1745,87 @@ func Main() {
 			},
 			Action: edgePublishAction,
 		}},
+	}, {
+		Name:  "versions",
+		Usage: "lists versions of extension in the store",
+		Subcommands: []*cli.Command{{
+			Name:  "firefox",
+			Usage: "lists listed and unlisted versions of extension in the firefox store",
+			Flags: []cli.Flag{
+				appFlag,
+				&cli.StringFlag{
+					Name:    "channel",
+					Aliases: []string{"c"},
+					Usage:   "show only versions of the channel (listed or unlisted)",
+				},
+				&cli.StringFlag{
+					Name:  "status",
+					Usage: "show only versions with the file status, e.g. public, nominated or disabled",
+				},
+				formatFlag,
+			},
+			Action: firefoxVersionsAction,
+		}},
+	}, {
+		Name:  "download",
+		Usage: "downloads previously signed extension from the store",
+		Subcommands: []*cli.Command{{
+			Name:  "firefox",
+			Usage: "downloads signed version of extension from the firefox store",
+			Flags: []cli.Flag{
+				appFlag,
+				&cli.StringFlag{
+					Name:  "version",
+					Usage: "version of the extension to download, e.g. 1.2.3",
+				},
+				&cli.BoolFlag{
+					Name:  "all",
+					Usage: "download all signed versions to the output directory",
+				},
+				&cli.StringFlag{
+					Name:        "output",
+					Aliases:     []string{"o"},
+					Usage:       "output file, or output directory with --all",
+					DefaultText: "firefox-<version>.xpi, or current directory with --all",
+				},
+				requestTimeoutFlag,
+			},
+			Action: firefoxDownloadAction,
+		}},
+	}, {
+		Name:  "disable",
+		Usage: "disables version of extension in the store",
+		Subcommands: []*cli.Command{{
+			Name:   "firefox",
+			Usage:  "disables version of extension in the firefox store",
+			Flags:  []cli.Flag{appFlag, versionFlag},
+			Action: firefoxDisableAction,
+		}},
+	}, {
+		Name:  "enable",
+		Usage: "re-enables previously disabled version of extension in the store",
+		Subcommands: []*cli.Command{{
+			Name:   "firefox",
+			Usage:  "re-enables version of extension in the firefox store",
+			Flags:  []cli.Flag{appFlag, versionFlag},
+			Action: firefoxEnableAction,
+		}},
+	}, {
+		Name:  "delete",
+		Usage: "deletes unreviewed version of extension from the store",
+		Subcommands: []*cli.Command{{
+			Name:   "firefox",
+			Usage:  "deletes unreviewed version of extension from the firefox store",
+			Flags:  []cli.Flag{appFlag, versionFlag},
+			Action: firefoxDeleteAction,
+		}},
 	}, {
 		Name:  "sign",
 		Usage: "signs extension in the store",
 		Subcommands: []*cli.Command{{
 			Name:  "firefox",
 			Usage: "signs extension in the firefox store",
-			Flags: []cli.Flag{
+			Flags: slices.Concat([]cli.Flag{
 				fileFlag,
 				sourceFlag,
 				&cli.StringFlag{
@@ -690,9 +1835,39 @@ func Main() {
 					Required: false,
 				},
 				approvalNotesFlag,
-			},
+				failOnWarningsFlag,
+				formatFlag,
+				updateManifestFlag,
+				updateLinkFlag,
+				&cli.BoolFlag{
+					Name:  "no-wait",
+					Usage: "don't wait for signing, exit once the version is submitted or found pending",
+				},
+			}, sourceDirFlags, firefoxPollFlags),
 			Action: firefoxSignAction,
 		}},
+	}, {
+		Name:  "update-manifest",
+		Usage: "adds signed extension to the self-hosted update manifest",
+		Subcommands: []*cli.Command{{
+			Name:  "firefox",
+			Usage: "adds signed xpi to the firefox update manifest (updates.json)",
+			Flags: []cli.Flag{
+				fileFlag,
+				&cli.StringFlag{
+					Name:     updateManifestFlag.Name,
+					Aliases:  updateManifestFlag.Aliases,
+					Usage:    updateManifestFlag.Usage,
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     updateLinkFlag.Name,
+					Usage:    updateLinkFlag.Usage,
+					Required: true,
+				},
+			},
+			Action: firefoxUpdateManifestAction,
+		}},
 	}}
 
 	err := app.Run(os.Args)
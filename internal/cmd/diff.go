@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/adguardteam/go-webext/internal/pkgdiff"
+	"github.com/urfave/cli/v2"
+)
+
+// diffAction compares the old and the new package and prints the changed
+// files, manifest fields and permissions.
+func diffAction(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return errors.Error("expected the old and the new package to compare")
+	}
+
+	report, err := pkgdiff.Diff(c.Args().Get(0), c.Args().Get(1))
+	if err != nil {
+		return fmt.Errorf("comparing packages: %w", err)
+	}
+
+	if c.String("format") == formatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "    ")
+		enc.SetEscapeHTML(false)
+
+		err = enc.Encode(report)
+		if err != nil {
+			return fmt.Errorf("encoding report: %w", err)
+		}
+	} else {
+		printPackageDiff(report)
+	}
+
+	if report.HasEscalation() && c.Bool("fail-on-new-permissions") {
+		return errors.Error("new package requests new permissions")
+	}
+
+	return nil
+}
+
+// printPackageDiff prints the report in the format of diff: "+" for the added
+// items, "-" for the removed ones and "~" for the changed ones.  The added
+// required permissions are marked, since they trigger an additional review.
+func printPackageDiff(r *pkgdiff.Report) {
+	fmt.Printf("Old: %s\n", r.Old)
+	fmt.Printf("New: %s\n", r.New)
+
+	fmt.Printf("\nFiles: %d added, %d removed, %d changed\n", len(r.Added), len(r.Removed), len(r.Changed))
+	for _, name := range r.Added {
+		fmt.Printf("  + %s\n", name)
+	}
+
+	for _, name := range r.Removed {
+		fmt.Printf("  - %s\n", name)
+	}
+
+	for _, name := range r.Changed {
+		fmt.Printf("  ~ %s\n", name)
+	}
+
+	fmt.Printf("\nManifest: %d field(s) changed\n", len(r.Manifest))
+	for _, f := range r.Manifest {
+		switch {
+		case f.Old == "":
+			fmt.Printf("  + %s: %s\n", f.Field, f.New)
+		case f.New == "":
+			fmt.Printf("  - %s: %s\n", f.Field, f.Old)
+		default:
+			fmt.Printf("  ~ %s: %s -> %s\n", f.Field, f.Old, f.New)
+		}
+	}
+
+	fmt.Printf("\nPermissions: %d change(s)\n", len(r.Permissions))
+	for _, p := range r.Permissions {
+		switch {
+		case !p.Added:
+			fmt.Printf("  - %s (%s)\n", p.Permission, p.Kind)
+		case p.Kind == pkgdiff.PermissionOptional:
+			fmt.Printf("  + %s (%s)\n", p.Permission, p.Kind)
+		default:
+			fmt.Printf("  + %s (%s)  NEW PERMISSION, may trigger an additional store review\n", p.Permission, p.Kind)
+		}
+	}
+}
@@ -0,0 +1,446 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/adguardteam/go-webext/internal/credentials"
+	"github.com/adguardteam/go-webext/internal/edge"
+	"github.com/adguardteam/go-webext/internal/extstore"
+	firefoxapi "github.com/adguardteam/go-webext/internal/firefox/api"
+	"github.com/urfave/cli/v2"
+)
+
+// doctorStatus is the outcome of a single doctor check.
+type doctorStatus string
+
+// Outcomes of the doctor checks.
+const (
+	doctorOK   doctorStatus = "ok"
+	doctorWarn doctorStatus = "warn"
+	doctorFail doctorStatus = "fail"
+	doctorSkip doctorStatus = "skip"
+)
+
+// Names of the doctor checks.
+const (
+	checkConfig = "config"
+	checkAuth   = "auth"
+	checkRead   = "read"
+)
+
+// doctorCheck is a single line of the doctor report.
+type doctorCheck struct {
+	Store   string       `json:"store"`
+	Check   string       `json:"check"`
+	Status  doctorStatus `json:"status"`
+	Message string       `json:"message,omitempty"`
+	Hint    string       `json:"hint,omitempty"`
+}
+
+// doctorReport collects the results of the doctor checks of a store.
+type doctorReport struct {
+	store  string
+	checks []doctorCheck
+}
+
+// add adds the result of the check to the report.
+func (r *doctorReport) add(check string, status doctorStatus, message, hint string) {
+	r.checks = append(r.checks, doctorCheck{
+		Store:   r.store,
+		Check:   check,
+		Status:  status,
+		Message: message,
+		Hint:    hint,
+	})
+}
+
+// fail adds the failed check with the error message to the report.  Joined
+// errors are put on a single line.
+func (r *doctorReport) fail(check string, err error, hint string) {
+	r.add(check, doctorFail, strings.ReplaceAll(err.Error(), "\n", "; "), hint)
+}
+
+// sourcesHint is the hint for the errors of looking up the credentials.
+const sourcesHint = "check the secrets file, the secrets command and the profile"
+
+// lookupCredential returns the value of the secret from the sources set up in
+// Main, or an empty string if it's not set.
+func lookupCredential(c *cli.Context, key string) (value string, err error) {
+	return lookupValue(c, key, true)
+}
+
+// lookupSetting is like lookupCredential, but for the settings which aren't
+// secrets, so the secrets command isn't asked for them.
+func lookupSetting(c *cli.Context, key string) (value string, err error) {
+	return lookupValue(c, key, false)
+}
+
+// lookupValue returns the value of the credential from the sources set up in
+// Main, or an empty string if it's not set.
+func lookupValue(c *cli.Context, key string, secret bool) (value string, err error) {
+	r, ok := c.App.Metadata[credentialsMetadataKey].(*credentials.Resolver)
+	if !ok {
+		return "", nil
+	}
+
+	lookup := r.Lookup
+	if secret {
+		lookup = r.LookupSecret
+	}
+
+	value, _, err = lookup(key)
+
+	return value, err
+}
+
+// isConfigured returns true if any of the credentials is set.  It returns an
+// error if any of them can't be looked up, e.g. because the secrets command
+// fails, so that a broken source isn't reported as missing credentials.  The
+// keys with credentials.FileEnvSuffix are paths, so they aren't looked up as
+// secrets.
+func isConfigured(c *cli.Context, keys ...string) (ok bool, err error) {
+	var errs []error
+	for _, key := range keys {
+		var value string
+		value, err = lookupValue(c, key, !strings.HasSuffix(key, credentials.FileEnvSuffix))
+		if err != nil {
+			errs = append(errs, err)
+		} else if value != "" {
+			ok = true
+		}
+	}
+
+	return ok, errors.Join(errs...)
+}
+
+// checkConfigured adds the config check to the report if the store isn't
+// configured or its credentials can't be looked up.  It returns true if the
+// other checks should be run.
+func checkConfigured(c *cli.Context, r *doctorReport, keys ...string) (ok bool) {
+	ok, err := isConfigured(c, keys...)
+	if err != nil {
+		r.fail(checkConfig, err, sourcesHint)
+
+		return false
+	} else if !ok {
+		r.add(checkConfig, doctorSkip, "not configured", "")
+	}
+
+	return ok
+}
+
+// doctorAppID returns the item ID from the --<store>-app flag or from the
+// selected profile.
+func doctorAppID(c *cli.Context, store string) (appID string) {
+	return cmp.Or(c.String(store+"-app"), profileAppID(c, store))
+}
+
+// doctorChrome checks the Chrome Web Store credentials.
+func doctorChrome(c *cli.Context) (r *doctorReport) {
+	r = &doctorReport{store: extstore.Chrome}
+
+	if !checkConfigured(
+		c,
+		r,
+		"CHROME_CLIENT_ID",
+		"CHROME_SERVICE_ACCOUNT_KEY",
+		"CHROME_SERVICE_ACCOUNT_KEY_FILE",
+	) {
+		return r
+	}
+
+	const configHint = "set CHROME_CLIENT_ID, CHROME_CLIENT_SECRET and CHROME_REFRESH_TOKEN, " +
+		"or CHROME_SERVICE_ACCOUNT_KEY_FILE; CHROME_PUBLISHER_ID is required for the v2 API"
+
+	cfg, err := newChromeConfig(c)
+	if err != nil {
+		r.fail(checkConfig, err, configHint)
+
+		return r
+	}
+
+	chromeLogger := slog.Default().With(slogutil.KeyPrefix, "chrome")
+
+	authorizer, err := newChromeAuthorizer(cfg, chromeLogger)
+	if err != nil {
+		r.fail(checkConfig, err, configHint)
+
+		return r
+	}
+
+	store, err := newChromeStore(cfg, authorizer, chromeLogger)
+	if err != nil {
+		r.fail(checkConfig, err, configHint)
+
+		return r
+	}
+
+	kind, authHint := "refresh token", "the refresh token may be revoked or expired, run `go-webext auth chrome`"
+	if cfg.hasServiceAccount() {
+		kind, authHint = "service account", "check that the service account key is valid and not disabled"
+	}
+
+	r.add(checkConfig, doctorOK, fmt.Sprintf("api %s, %s credentials", store.apiVersion, kind), "")
+
+	_, err = authorizer.Authorize()
+	if err != nil {
+		r.fail(checkAuth, err, authHint)
+
+		return r
+	}
+
+	r.add(checkAuth, doctorOK, "access token obtained", "")
+
+	appID := doctorAppID(c, extstore.Chrome)
+	if appID == "" {
+		r.add(checkRead, doctorSkip, "no item id", "set --chrome-app or the chrome item id in the profile")
+
+		return r
+	}
+
+	if store.apiVersion == chromeAPIVersionV2 {
+		_, err = store.v2.Status(appID)
+	} else {
+		_, err = store.v1.Status(appID)
+	}
+
+	if err != nil {
+		r.fail(checkRead, err, "check the item id and that the account has access to it")
+
+		return r
+	}
+
+	r.add(checkRead, doctorOK, "status of item "+appID+" received", "")
+
+	return r
+}
+
+// doctorFirefox checks the AMO credentials.
+func doctorFirefox(c *cli.Context) (r *doctorReport) {
+	r = &doctorReport{store: extstore.Firefox}
+
+	if !checkConfigured(c, r, "FIREFOX_CLIENT_ID", "FIREFOX_CLIENT_SECRET") {
+		return r
+	}
+
+	const credentialsHint = "get the credentials at https://addons.mozilla.org/developers/addon/api/key/"
+
+	store, err := getFirefoxStore(c)
+	if err != nil {
+		r.fail(checkConfig, err, "set FIREFOX_CLIENT_ID and FIREFOX_CLIENT_SECRET, "+credentialsHint)
+
+		return r
+	}
+
+	clientID, idErr := lookupCredential(c, "FIREFOX_CLIENT_ID")
+	clientSecret, secretErr := lookupCredential(c, "FIREFOX_CLIENT_SECRET")
+	err = errors.Join(idErr, secretErr)
+	if err != nil {
+		r.fail(checkConfig, err, sourcesHint)
+
+		return r
+	}
+
+	err = firefoxapi.CheckCredentials(clientID, clientSecret)
+	if err != nil {
+		r.add(checkConfig, doctorWarn, err.Error(), "check that the values aren't swapped or truncated, "+credentialsHint)
+	} else {
+		r.add(checkConfig, doctorOK, "credentials look valid", "")
+	}
+
+	amoProfile, err := store.WhoAmI()
+	if err != nil {
+		r.fail(checkAuth, err, "check the local clock and regenerate the credentials if they were revoked")
+
+		return r
+	}
+
+	r.add(checkAuth, doctorOK, "authenticated as "+amoProfile.Username, "")
+
+	appID := doctorAppID(c, extstore.Firefox)
+	if appID == "" {
+		r.add(checkRead, doctorSkip, "no add-on id", "set --firefox-app or the firefox add-on id in the profile")
+
+		return r
+	}
+
+	_, err = store.Status(appID)
+	if err != nil {
+		r.fail(checkRead, err, "check the add-on id and that the account is its author")
+
+		return r
+	}
+
+	r.add(checkRead, doctorOK, "status of add-on "+appID+" received", "")
+
+	return r
+}
+
+// doctorEdgeKeyExpiry checks the expiry of the Edge v1.1 API key.
+func doctorEdgeKeyExpiry(c *cli.Context, r *doctorReport) {
+	const check = "api key expiry"
+
+	expiry, _, err := getEdgeKeyExpiry(c)
+	if err != nil {
+		r.fail(check, err, "")
+
+		return
+	}
+
+	if expiry == nil {
+		r.add(
+			check,
+			doctorWarn,
+			"expiry date is unknown",
+			"Edge API keys expire every 72 days, set EDGE_API_KEY_ISSUED or EDGE_API_KEY_EXPIRES to track it",
+		)
+
+		return
+	}
+
+	expires := expiry.ExpiresAt.Format(time.DateOnly)
+	left, err := expiry.Check(time.Now())
+	switch {
+	case errors.Is(err, edge.ErrAPIKeyExpired):
+		r.fail(check, err, "create a new api key in Partner Center and update EDGE_API_KEY_ISSUED")
+	case err != nil:
+		r.add(check, doctorWarn, err.Error(), "schedule the rotation of the api key in Partner Center")
+	default:
+		r.add(check, doctorOK, fmt.Sprintf("expires on %s, in %d days", expires, edge.DaysLeft(left)), "")
+	}
+}
+
+// doctorEdge checks the Edge Add-ons credentials.
+func doctorEdge(c *cli.Context) (r *doctorReport) {
+	r = &doctorReport{store: extstore.Edge}
+
+	if !checkConfigured(c, r, "EDGE_CLIENT_ID") {
+		return r
+	}
+
+	store, err := getEdgeStore(c)
+	if err != nil {
+		r.fail(checkConfig, err, "set EDGE_CLIENT_ID and EDGE_API_KEY for the v1.1 API")
+
+		return r
+	}
+
+	apiVersion, err := lookupSetting(c, "EDGE_API_VERSION")
+	if err != nil {
+		r.fail(checkConfig, err, sourcesHint)
+
+		return r
+	}
+
+	apiVersion = cmp.Or(apiVersion, edge.APIVersionV1)
+	if apiVersion == edge.APIVersionV1 {
+		r.add(
+			checkConfig,
+			doctorWarn,
+			"api v1 uses deprecated client secrets",
+			"switch to the v1.1 API with EDGE_API_KEY and EDGE_API_VERSION=v1.1",
+		)
+	} else {
+		doctorEdgeKeyExpiry(c, r)
+	}
+
+	if apiVersion == edge.APIVersionV1 {
+		err = store.Authorize()
+		if err != nil {
+			r.fail(checkAuth, err, "check EDGE_CLIENT_SECRET and EDGE_ACCESS_TOKEN_URL")
+
+			return r
+		}
+
+		r.add(checkAuth, doctorOK, "access token obtained", "")
+	}
+
+	appID := doctorAppID(c, extstore.Edge)
+	if appID == "" {
+		r.add(checkRead, doctorSkip, "no product id", "set --edge-app or the edge product id in the profile")
+
+		return r
+	}
+
+	err = store.CheckAccess(appID)
+	if err != nil {
+		hint := "check the product id"
+		if errors.Is(err, edge.ErrUnauthorized) {
+			hint = "the credentials are rejected, the api key may be expired, create a new one in Partner Center"
+		}
+
+		r.fail(checkRead, err, hint)
+
+		return r
+	}
+
+	r.add(checkRead, doctorOK, "credentials accepted for product "+appID, "")
+
+	return r
+}
+
+// doctorAction checks the credentials and connectivity of every configured
+// store and reports the results with remediation hints.
+func doctorAction(c *cli.Context) error {
+	var checks []doctorCheck
+	for _, check := range []func(c *cli.Context) *doctorReport{doctorChrome, doctorFirefox, doctorEdge} {
+		checks = append(checks, check(c).checks...)
+	}
+
+	failed := 0
+	for _, check := range checks {
+		if check.Status == doctorFail {
+			failed++
+		}
+	}
+
+	if c.String("format") == formatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "    ")
+
+		err := enc.Encode(checks)
+		if err != nil {
+			return fmt.Errorf("encoding report: %w", err)
+		}
+	} else {
+		printDoctorReport(checks)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("doctor found %d problem(s)", failed)
+	}
+
+	return nil
+}
+
+// printDoctorReport prints the doctor report as a table with the hints below
+// the checks.
+func printDoctorReport(checks []doctorCheck) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "STORE\tCHECK\tSTATUS\tMESSAGE")
+	for _, check := range checks {
+		_, _ = fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%s\n",
+			check.Store,
+			check.Check,
+			strings.ToUpper(string(check.Status)),
+			check.Message,
+		)
+
+		if check.Hint != "" {
+			_, _ = fmt.Fprintf(w, "\t\t\t  hint: %s\n", check.Hint)
+		}
+	}
+
+	_ = w.Flush()
+}
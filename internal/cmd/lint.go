@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/adguardteam/go-webext/internal/extversion"
+	"github.com/adguardteam/go-webext/internal/lint"
+	"github.com/urfave/cli/v2"
+)
+
+// lintAction returns the action checking the package against the rules of
+// the store.  If the item ID is known and --offline isn't set, the package
+// version is compared with the current version in the store.
+func lintAction(store string) (action cli.ActionFunc) {
+	return func(c *cli.Context) error {
+		conf := lint.Config{
+			Store:   store,
+			Path:    c.String("file"),
+			MaxSize: c.Int64("max-size"),
+		}
+
+		appID := cmp.Or(c.String("app"), profileAppID(c, store))
+		if appID != "" && !c.Bool("offline") {
+			version, err := currentStoreVersion(c, store, appID)
+			switch {
+			case errors.Is(err, extversion.ErrNoStoreAPI):
+				slog.Info("skipping comparison with the store version", slogutil.KeyError, err)
+			case err != nil:
+				return fmt.Errorf("getting store version: %w", err)
+			default:
+				conf.CurrentVersion = version
+			}
+		}
+
+		report, err := lint.Lint(conf)
+		if err != nil {
+			return fmt.Errorf("linting package: %w", err)
+		}
+
+		if c.String("format") == formatJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "    ")
+
+			err = enc.Encode(report)
+			if err != nil {
+				return fmt.Errorf("encoding report: %w", err)
+			}
+		} else {
+			printLintReport(report)
+		}
+
+		if report.HasErrors() {
+			return errors.Error("package has errors")
+		}
+
+		return nil
+	}
+}
+
+// printLintReport prints the lint report as a table.
+func printLintReport(r *lint.Report) {
+	fmt.Printf("Package: %s\nStore: %s\n", r.Path, r.Store)
+	if r.Version != "" {
+		fmt.Printf("Version: %s\n", r.Version)
+	}
+
+	if len(r.Issues) == 0 {
+		fmt.Println("No issues found")
+
+		return
+	}
+
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SEVERITY\tRULE\tFILE\tMESSAGE")
+	for _, i := range r.Issues {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", i.Severity, i.Rule, i.File, i.Message)
+	}
+
+	_ = w.Flush()
+}
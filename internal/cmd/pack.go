@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/adguardteam/go-webext/internal/pack"
+	"github.com/urfave/cli/v2"
+)
+
+// packAction returns the action building the package for the store from the
+// directory.  The output defaults to <store>.zip in the current directory.
+func packAction(store string) (action cli.ActionFunc) {
+	return func(c *cli.Context) error {
+		output := cmp.Or(c.String("output"), store+".zip")
+
+		res, err := pack.Build(pack.Config{
+			Logger:   slog.Default().With(slogutil.KeyPrefix, "pack"),
+			Store:    store,
+			Dir:      c.String("dir"),
+			Overlays: c.StringSlice("overlay"),
+			Exclude:  c.StringSlice("exclude"),
+		}, output)
+		if err != nil {
+			return fmt.Errorf("building %s package: %w", store, err)
+		}
+
+		fmt.Printf("Package: %s\n", output)
+		fmt.Printf("Name: %s\n", res.Manifest.Name)
+		fmt.Printf("Version: %s\n", res.Manifest.Version)
+		fmt.Printf("Files: %d\n", res.Files)
+		fmt.Printf("Size: %d\n", res.Size)
+
+		return nil
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/adguardteam/go-webext/internal/fileutil"
+	"github.com/urfave/cli/v2"
+)
+
+// verifyReproducibleAction compares two archives entry by entry, e.g. the
+// package built locally with the one built by the release pipeline, and
+// fails if they aren't identical.
+func verifyReproducibleAction(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return errors.Error("expected two archives to compare")
+	}
+
+	first, second := c.Args().Get(0), c.Args().Get(1)
+	diffs, err := fileutil.CompareZips(first, second)
+	if err != nil {
+		return fmt.Errorf("comparing archives: %w", err)
+	}
+
+	if c.String("format") == formatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "    ")
+
+		err = enc.Encode(struct {
+			Differences  []fileutil.ZipDiff `json:"differences"`
+			Reproducible bool               `json:"reproducible"`
+		}{
+			Differences:  append([]fileutil.ZipDiff{}, diffs...),
+			Reproducible: len(diffs) == 0,
+		})
+		if err != nil {
+			return fmt.Errorf("encoding report: %w", err)
+		}
+	} else {
+		printZipDiffs(diffs)
+	}
+
+	if len(diffs) > 0 {
+		return fmt.Errorf("archives differ in %d place(s)", len(diffs))
+	}
+
+	return nil
+}
+
+// printZipDiffs prints the differences between the archives as a table.
+func printZipDiffs(diffs []fileutil.ZipDiff) {
+	if len(diffs) == 0 {
+		fmt.Println("Archives are identical")
+
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ENTRY\tDIFFERENCE\tFIRST\tSECOND")
+	for _, d := range diffs {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.Name, d.Kind, d.First, d.Second)
+	}
+
+	_ = w.Flush()
+}
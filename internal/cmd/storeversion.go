@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/adguardteam/go-webext/internal/chrome"
+	"github.com/adguardteam/go-webext/internal/extstore"
+	"github.com/adguardteam/go-webext/internal/extversion"
+	"github.com/adguardteam/go-webext/internal/firefox"
+	"github.com/adguardteam/go-webext/internal/manifest"
+	"github.com/urfave/cli/v2"
+)
+
+// currentStoreVersion initializes the store client and returns the latest
+// version of the item in the store, either published or submitted for review.
+// It returns an error wrapping extversion.ErrNoStoreAPI for Edge.
+func currentStoreVersion(c *cli.Context, store, appID string) (version string, err error) {
+	switch store {
+	case extstore.Chrome:
+		chromeStore, err := getChromeStore(c)
+		if err != nil {
+			return "", fmt.Errorf("initializing chrome store: %w", err)
+		}
+
+		return currentChromeVersion(chromeStore, appID)
+	case extstore.Firefox:
+		firefoxStore, err := getFirefoxStore(c)
+		if err != nil {
+			return "", fmt.Errorf("initializing firefox store: %w", err)
+		}
+
+		return firefoxStoreVersion(firefoxStore, appID)()
+	default:
+		return "", fmt.Errorf("%s: %w", store, extversion.ErrNoStoreAPI)
+	}
+}
+
+// firefoxStoreVersion returns a function returning the current version of the
+// add-on in store.
+func firefoxStoreVersion(store *firefox.Store, appID string) (f func() (version string, err error)) {
+	return func() (version string, err error) {
+		status, err := store.Status(appID)
+		if err != nil {
+			return "", fmt.Errorf("getting status: %w", err)
+		}
+
+		return status.CurrentVersion, nil
+	}
+}
+
+// edgeStoreVersion returns an error wrapping extversion.ErrNoStoreAPI, since
+// the Edge API doesn't report the version of the product.
+func edgeStoreVersion() (version string, err error) {
+	return "", fmt.Errorf("%s: %w", extstore.Edge, extversion.ErrNoStoreAPI)
+}
+
+// chromeStoreVersion returns a function returning the latest version of the
+// item in store, either published or submitted for review.
+func chromeStoreVersion(store *chromeStore, appID string) (f func() (version string, err error)) {
+	return func() (version string, err error) {
+		return currentChromeVersion(store, appID)
+	}
+}
+
+// currentChromeVersion returns the latest version of the Chrome item.
+func currentChromeVersion(store *chromeStore, appID string) (version string, err error) {
+	if store.apiVersion == chromeAPIVersionV1 {
+		status, statusErr := store.v1.Status(appID)
+		if statusErr != nil {
+			return "", fmt.Errorf("getting status: %w", statusErr)
+		}
+
+		return status.CrxVersion, nil
+	}
+
+	status, err := store.v2.Status(appID)
+	if err != nil {
+		return "", fmt.Errorf("getting status: %w", err)
+	}
+
+	var versions []string
+	for _, rev := range []*chrome.ItemRevisionStatus{
+		status.PublishedItemRevisionStatus,
+		status.SubmittedItemRevisionStatus,
+	} {
+		if rev == nil {
+			continue
+		}
+
+		for _, ch := range rev.DistributionChannels {
+			versions = append(versions, ch.CrxVersion)
+		}
+	}
+
+	return maxVersion(versions), nil
+}
+
+// maxVersion returns the greatest of the versions, skipping the ones that
+// can't be parsed.
+func maxVersion(versions []string) (latest string) {
+	var latestVer extversion.Version
+	for _, s := range versions {
+		v, err := extversion.Parse(s)
+		if err != nil {
+			continue
+		}
+
+		if latestVer == nil || extversion.Compare(v, latestVer) > 0 {
+			latest, latestVer = s, v
+		}
+	}
+
+	return latest
+}
+
+// readPackageManifest reads and validates the manifest of the package before
+// it's uploaded to the store and logs the extension it describes.
+func readPackageManifest(store, pkgPath string) (m *manifest.Manifest, err error) {
+	m, err = manifest.ReadZip(pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+
+	err = m.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	slog.Info(
+		"package manifest",
+		"store", store,
+		"name", m.Name,
+		"version", m.Version,
+		"version_name", m.VersionName,
+		"manifest_version", m.ManifestVersion,
+	)
+
+	return m, nil
+}
+
+// checkVersionIncrease returns an error if the version of the package isn't
+// greater than the current version of the item in the store, see
+// extversion.CheckIncrease.  storeVersion returns the current version.
+func checkVersionIncrease(
+	c *cli.Context,
+	store string,
+	appID string,
+	m *manifest.Manifest,
+	storeVersion func() (version string, err error),
+) (err error) {
+	return extversion.CheckIncrease(extversion.IncreaseConfig{
+		Logger:       slog.Default().With("action", "checkVersionIncrease", "store", store, "app_id", appID),
+		StoreVersion: storeVersion,
+		Version:      m.Version,
+		Force:        c.Bool("force"),
+	})
+}
@@ -0,0 +1,247 @@
+// Package credentials resolves the credentials of the stores from several
+// sources: the environment, files referenced by *_FILE variables, a secrets
+// file and, for secrets, an external command.
+package credentials
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env/v6"
+)
+
+// Provider is a source of credentials.
+type Provider interface {
+	// Name returns the human-readable name of the provider used in logs.
+	Name() (name string)
+
+	// Lookup returns the value of the credential with the given key.  ok is
+	// false if the provider doesn't have it.
+	Lookup(key string) (value string, ok bool, err error)
+}
+
+// SecretTag is the struct tag marking the fields parsed by Parse as secrets,
+// e.g. `env:"CHROME_REFRESH_TOKEN" secret:"true"`.  Only secrets are looked up
+// by the secrets command.
+const SecretTag = "secret"
+
+// DefaultCommandTimeout is the default timeout of a single run of the secrets
+// command.
+const DefaultCommandTimeout = 30 * time.Second
+
+// Config describes the sources of credentials.  Empty fields mean the
+// corresponding provider isn't used.
+type Config struct {
+	// Logger is used to log where the credentials are resolved from.  The
+	// values themselves are never logged.
+	Logger *slog.Logger
+
+	// LookupEnv looks up the environment variables.  If nil, os.LookupEnv is
+	// used.
+	LookupEnv func(key string) (value string, ok bool)
+
+	// SecretsFile is the path to the JSON or YAML file with the mapping of the
+	// keys to the values.
+	SecretsFile string
+
+	// SecretsCommand is the shell command printing the value of the secret
+	// passed in the CommandKeyEnv environment variable.
+	SecretsCommand string
+
+	// SecretsCommandTimeout is the timeout of a single run of SecretsCommand.
+	// If zero, DefaultCommandTimeout is used.
+	SecretsCommandTimeout time.Duration
+
+	// Profile contains the values of the selected profile, which take
+	// precedence over all other sources.  The ${VAR} references in them are
+	// expanded using the other sources.  The secrets of the stores the profile
+	// sets any value for, e.g. CHROME_* for CHROME_CLIENT_ID, aren't looked
+	// up in the other sources, so that the credentials of different accounts
+	// aren't mixed.
+	Profile map[string]string
+
+	// ProfileName is the name of the selected profile used in the errors.
+	ProfileName string
+}
+
+// Resolver looks up credentials in its providers in the order of precedence:
+// the profile, the environment, files referenced by *_FILE variables, the
+// secrets file and, for secrets only, the secrets command.
+type Resolver struct {
+	logger *slog.Logger
+	// profile is the provider of the selected profile, if any.  It's also the
+	// first one of providers.
+	profile     *profileProvider
+	profileName string
+	providers   []Provider
+	// secretProviders are only used to look up secrets, after providers.
+	secretProviders []Provider
+}
+
+// NewResolver creates a new resolver.  It returns an error if the secrets
+// file can't be read.
+func NewResolver(conf Config) (r *Resolver, err error) {
+	lookupEnv := conf.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	r = &Resolver{
+		logger: conf.Logger,
+		providers: []Provider{
+			envProvider(lookupEnv),
+			fileEnvProvider(lookupEnv),
+		},
+	}
+
+	if conf.SecretsFile != "" {
+		var p Provider
+		p, err = newSecretsFileProvider(conf.SecretsFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading secrets file: %w", err)
+		}
+
+		r.providers = append(r.providers, p)
+	}
+
+	if conf.SecretsCommand != "" {
+		r.secretProviders = append(r.secretProviders, &commandProvider{
+			logger:  conf.Logger,
+			command: conf.SecretsCommand,
+			timeout: cmp.Or(conf.SecretsCommandTimeout, DefaultCommandTimeout),
+		})
+	}
+
+	if conf.Profile != nil {
+		base := &Resolver{
+			logger:          r.logger,
+			providers:       r.providers,
+			secretProviders: r.secretProviders,
+		}
+
+		r.profile = &profileProvider{values: conf.Profile, base: base}
+		r.profileName = conf.ProfileName
+		r.providers = append([]Provider{r.profile}, r.providers...)
+	}
+
+	return r, nil
+}
+
+// Lookup returns the value of the credential with the given key from the
+// first provider having it.  The secrets command isn't used, see
+// LookupSecret.
+func (r *Resolver) Lookup(key string) (value string, ok bool, err error) {
+	return r.lookup(key, false)
+}
+
+// LookupSecret is like Lookup, but also uses the secrets command.
+func (r *Resolver) LookupSecret(key string) (value string, ok bool, err error) {
+	return r.lookup(key, true)
+}
+
+// lookup returns the value of the credential from the first provider having
+// it, the secret providers are only used if secret is true.  A key set in the
+// selected profile, even to an empty value, is never looked up in the other
+// providers, so that a profile can unset a global value.  It returns an error
+// if the secret is missing in the selected profile, which covers its store,
+// but is set globally.
+func (r *Resolver) lookup(key string, secret bool) (value string, ok bool, err error) {
+	providers := r.providers
+	if secret {
+		providers = slices.Concat(r.providers, r.secretProviders)
+	}
+
+	for _, p := range providers {
+		value, ok, err = p.Lookup(key)
+		if err != nil {
+			return "", false, fmt.Errorf("looking up %s in %s: %w", key, p.Name(), err)
+		}
+
+		if !ok && p == Provider(r.profile) && r.profile.has(key) {
+			r.logger.Debug("credential unset by profile", "key", key)
+
+			return "", false, nil
+		}
+
+		if ok && secret && r.profile != nil && p != Provider(r.profile) && r.profile.covers(key) {
+			return "", false, fmt.Errorf(
+				"profile %q doesn't set %s; set it or reference the global value as ${%[2]s}",
+				r.profileName,
+				key,
+			)
+		}
+
+		if ok {
+			r.logger.Debug("credential resolved", "key", key, "source", p.Name(), "value", Redact(value))
+
+			return value, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// Parse fills the struct pointed to by v from the credentials with the keys
+// from its env tags, the way env.Parse does for the environment.  The fields
+// marked with SecretTag are looked up as secrets.
+func (r *Resolver) Parse(v any) (err error) {
+	environment := map[string]string{}
+	for _, k := range envKeys(reflect.TypeOf(v)) {
+		var value string
+		var ok bool
+		value, ok, err = r.lookup(k.name, k.secret)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			environment[k.name] = value
+		}
+	}
+
+	return env.Parse(v, env.Options{Environment: environment})
+}
+
+// envKey is a key from the env tag of a struct field.
+type envKey struct {
+	name   string
+	secret bool
+}
+
+// envKeys returns the keys from the env tags of the fields of the struct type
+// t or the type it points to.
+func envKeys(t reflect.Type) (keys []envKey) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if name, _, _ := strings.Cut(f.Tag.Get("env"), ","); name != "" {
+			keys = append(keys, envKey{name: name, secret: f.Tag.Get(SecretTag) == "true"})
+		} else if f.Type.Kind() == reflect.Struct {
+			keys = append(keys, envKeys(f.Type)...)
+		}
+	}
+
+	return keys
+}
+
+// Redact returns the redacted value for logs, keeping only its length.
+func Redact(value string) (redacted string) {
+	if value == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("[redacted, %d chars]", len(value))
+}
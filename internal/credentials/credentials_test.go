@@ -0,0 +1,309 @@
+package credentials_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/adguardteam/go-webext/internal/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLookupEnv returns the function looking up the variables in vars.
+func newLookupEnv(vars map[string]string) (lookupEnv func(key string) (string, bool)) {
+	return func(key string) (value string, ok bool) {
+		value, ok = vars[key]
+
+		return value, ok
+	}
+}
+
+func TestResolver_Lookup_precedence(t *testing.T) {
+	dir := t.TempDir()
+
+	mounted := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(mounted, []byte("from-file\n"), 0o600))
+
+	secrets := filepath.Join(dir, "secrets.yaml")
+	require.NoError(t, os.WriteFile(secrets, []byte("A: from-secrets\nB: from-secrets\nC: from-secrets\n"), 0o600))
+
+	r, err := credentials.NewResolver(credentials.Config{
+		Logger: slogutil.NewDiscardLogger(),
+		LookupEnv: newLookupEnv(map[string]string{
+			"A":      "from-env",
+			"A_FILE": mounted,
+			"B_FILE": mounted,
+		}),
+		SecretsFile: secrets,
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		key    string
+		want   string
+		wantOK bool
+	}{{
+		key:    "A",
+		want:   "from-env",
+		wantOK: true,
+	}, {
+		key:    "B",
+		want:   "from-file",
+		wantOK: true,
+	}, {
+		key:    "C",
+		want:   "from-secrets",
+		wantOK: true,
+	}, {
+		key:    "D",
+		want:   "",
+		wantOK: false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			value, ok, lookupErr := r.Lookup(tc.key)
+			require.NoError(t, lookupErr)
+
+			assert.Equal(t, tc.wantOK, ok)
+			assert.Equal(t, tc.want, value)
+		})
+	}
+}
+
+func TestResolver_Parse(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command requires sh")
+	}
+
+	secrets := filepath.Join(t.TempDir(), "secrets.json")
+	require.NoError(t, os.WriteFile(secrets, []byte(`{"CLIENT_ID": "id"}`), 0o600))
+
+	r, err := credentials.NewResolver(credentials.Config{
+		Logger:      slogutil.NewDiscardLogger(),
+		LookupEnv:   newLookupEnv(nil),
+		SecretsFile: secrets,
+		// The command fails for any key except CLIENT_SECRET and the optional
+		// REFRESH_TOKEN, so it must not be asked for the settings, which
+		// aren't secrets.
+		SecretsCommand: `case "$GO_WEBEXT_SECRET_KEY" in
+			CLIENT_SECRET) echo secret ;;
+			REFRESH_TOKEN) exit 100 ;;
+			*) exit 1 ;;
+		esac`,
+	})
+	require.NoError(t, err)
+
+	cfg := struct {
+		ClientID     string `env:"CLIENT_ID,notEmpty" secret:"true"`
+		ClientSecret string `env:"CLIENT_SECRET,notEmpty" secret:"true"`
+		RefreshToken string `env:"REFRESH_TOKEN" secret:"true"`
+		Version      string `env:"VERSION" envDefault:"v1"`
+	}{}
+
+	err = r.Parse(&cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "id", cfg.ClientID)
+	assert.Equal(t, "secret", cfg.ClientSecret)
+	assert.Empty(t, cfg.RefreshToken)
+	assert.Equal(t, "v1", cfg.Version)
+}
+
+func TestResolver_LookupSecret_command(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test commands require sh")
+	}
+
+	testCases := []struct {
+		name       string
+		command    string
+		wantErrMsg string
+		want       string
+		wantOK     bool
+	}{{
+		name:       "found",
+		command:    `echo "value of $GO_WEBEXT_SECRET_KEY"`,
+		wantErrMsg: "",
+		want:       "value of KEY",
+		wantOK:     true,
+	}, {
+		name:       "not_found",
+		command:    `true`,
+		wantErrMsg: "",
+		want:       "",
+		wantOK:     false,
+	}, {
+		name:       "not_found_exit_code",
+		command:    `exit 100`,
+		wantErrMsg: "",
+		want:       "",
+		wantOK:     false,
+	}, {
+		name:       "failed",
+		command:    `echo "vault is sealed" >&2; exit 2`,
+		wantErrMsg: "secrets command failed: exit status 2: vault is sealed",
+		want:       "",
+		wantOK:     false,
+	}, {
+		name:       "timeout",
+		command:    `sleep 10`,
+		wantErrMsg: "secrets command timed out after 100ms",
+		want:       "",
+		wantOK:     false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := credentials.NewResolver(credentials.Config{
+				Logger:                slogutil.NewDiscardLogger(),
+				LookupEnv:             newLookupEnv(nil),
+				SecretsCommand:        tc.command,
+				SecretsCommandTimeout: 100 * time.Millisecond,
+			})
+			require.NoError(t, err)
+
+			value, ok, err := r.LookupSecret("KEY")
+			if tc.wantErrMsg != "" {
+				assert.ErrorContains(t, err, tc.wantErrMsg)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.wantOK, ok)
+			assert.Equal(t, tc.want, value)
+
+			// The command is only used for secrets.
+			_, ok, err = r.Lookup("KEY")
+			require.NoError(t, err)
+
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, "", credentials.Redact(""))
+	assert.Equal(t, "[redacted, 6 chars]", credentials.Redact("secret"))
+}
+
+func TestResolver_Lookup_profile(t *testing.T) {
+	r, err := credentials.NewResolver(credentials.Config{
+		Logger: slogutil.NewDiscardLogger(),
+		LookupEnv: newLookupEnv(map[string]string{
+			"CLIENT_ID":   "default-id",
+			"BETA_SECRET": "beta-secret",
+		}),
+		Profile: map[string]string{
+			"CLIENT_ID":     "beta-id",
+			"CLIENT_SECRET": "${BETA_SECRET}",
+			"REFRESH_TOKEN": "${BETA_REFRESH_TOKEN}",
+		},
+	})
+	require.NoError(t, err)
+
+	value, ok, err := r.Lookup("CLIENT_ID")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	assert.Equal(t, "beta-id", value)
+
+	value, ok, err = r.Lookup("CLIENT_SECRET")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	assert.Equal(t, "beta-secret", value)
+
+	_, _, err = r.Lookup("REFRESH_TOKEN")
+	assert.ErrorContains(t, err, "BETA_REFRESH_TOKEN")
+}
+
+func TestResolver_LookupSecret_profileCoversStore(t *testing.T) {
+	r, err := credentials.NewResolver(credentials.Config{
+		Logger: slogutil.NewDiscardLogger(),
+		LookupEnv: newLookupEnv(map[string]string{
+			"CHROME_REFRESH_TOKEN": "default-token",
+			"EDGE_CLIENT_ID":       "default-edge-id",
+			"CHROME_API_VERSION":   "v2",
+		}),
+		Profile: map[string]string{
+			"CHROME_CLIENT_ID":     "beta-id",
+			"CHROME_CLIENT_SECRET": "beta-secret",
+		},
+		ProfileName: "beta",
+	})
+	require.NoError(t, err)
+
+	// The refresh token of the default account must not be used with the
+	// client of the profile.
+	_, ok, err := r.LookupSecret("CHROME_REFRESH_TOKEN")
+	assert.EqualError(
+		t,
+		err,
+		`profile "beta" doesn't set CHROME_REFRESH_TOKEN; set it or reference the global value as ${CHROME_REFRESH_TOKEN}`,
+	)
+	assert.False(t, ok)
+
+	// Missing everywhere.
+	_, ok, err = r.LookupSecret("CHROME_PUBLISHER_ID")
+	require.NoError(t, err)
+
+	assert.False(t, ok)
+
+	// The profile doesn't set any Edge credentials.
+	value, ok, err := r.LookupSecret("EDGE_CLIENT_ID")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	assert.Equal(t, "default-edge-id", value)
+
+	// The settings, which aren't secrets, are still global.
+	value, ok, err = r.Lookup("CHROME_API_VERSION")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	assert.Equal(t, "v2", value)
+}
+
+func TestResolver_LookupSecret_profileUnsets(t *testing.T) {
+	r, err := credentials.NewResolver(credentials.Config{
+		Logger: slogutil.NewDiscardLogger(),
+		LookupEnv: newLookupEnv(map[string]string{
+			"CHROME_REFRESH_TOKEN": "default-token",
+			"CHROME_PUBLISHER_ID":  "default-publisher",
+		}),
+		Profile: map[string]string{
+			"CHROME_SERVICE_ACCOUNT_KEY_FILE": "/run/secrets/beta.json",
+			"CHROME_REFRESH_TOKEN":            "",
+			"CHROME_PUBLISHER_ID":             "",
+		},
+		ProfileName: "beta",
+	})
+	require.NoError(t, err)
+
+	// The empty values in the profile unset the global ones instead of
+	// failing or falling through to them.
+	for _, key := range []string{"CHROME_REFRESH_TOKEN", "CHROME_PUBLISHER_ID"} {
+		value, ok, lookupErr := r.LookupSecret(key)
+		require.NoError(t, lookupErr, key)
+
+		assert.False(t, ok, key)
+		assert.Empty(t, value, key)
+	}
+
+	cfg := struct {
+		KeyFile      string `env:"CHROME_SERVICE_ACCOUNT_KEY_FILE" secret:"true"`
+		RefreshToken string `env:"CHROME_REFRESH_TOKEN" secret:"true"`
+		PublisherID  string `env:"CHROME_PUBLISHER_ID" secret:"true"`
+	}{}
+
+	require.NoError(t, r.Parse(&cfg))
+
+	assert.Equal(t, "/run/secrets/beta.json", cfg.KeyFile)
+	assert.Empty(t, cfg.RefreshToken)
+	assert.Empty(t, cfg.PublisherID)
+}
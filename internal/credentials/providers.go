@@ -0,0 +1,232 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnvSuffix is the suffix of the environment variables containing the
+// path to the file with the value of the credential, e.g. the files mounted by
+// CI systems.
+const FileEnvSuffix = "_FILE"
+
+// CommandNotFoundExitCode is the exit code of the secrets command meaning that
+// it has no value for the key, unlike other non-zero codes meaning a failure.
+const CommandNotFoundExitCode = 100
+
+// CommandKeyEnv is the environment variable containing the key to look up
+// for the secrets command.
+const CommandKeyEnv = "GO_WEBEXT_SECRET_KEY"
+
+// envProvider looks up the credentials in the environment, which also
+// contains the values loaded from the .env file.
+type envProvider func(key string) (value string, ok bool)
+
+// type check
+var _ Provider = envProvider(nil)
+
+// Name implements the Provider interface for envProvider.
+func (p envProvider) Name() (name string) { return "environment" }
+
+// Lookup implements the Provider interface for envProvider.  Empty variables
+// are treated as unset.
+func (p envProvider) Lookup(key string) (value string, ok bool, err error) {
+	value, ok = p(key)
+
+	return value, ok && value != "", nil
+}
+
+// fileEnvProvider reads the credentials from the files referenced by the
+// environment variables with FileEnvSuffix.
+type fileEnvProvider func(key string) (value string, ok bool)
+
+// type check
+var _ Provider = fileEnvProvider(nil)
+
+// Name implements the Provider interface for fileEnvProvider.
+func (p fileEnvProvider) Name() (name string) { return "*" + FileEnvSuffix + " file" }
+
+// Lookup implements the Provider interface for fileEnvProvider.  The trailing
+// newline of the file is trimmed.
+func (p fileEnvProvider) Lookup(key string) (value string, ok bool, err error) {
+	path, ok := p(key + FileEnvSuffix)
+	if !ok || path == "" {
+		return "", false, nil
+	}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", false, err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// secretsFileProvider looks up the credentials in the mapping loaded from a
+// JSON or YAML file.
+type secretsFileProvider struct {
+	values map[string]string
+	path   string
+}
+
+// type check
+var _ Provider = (*secretsFileProvider)(nil)
+
+// newSecretsFileProvider loads the secrets file.  JSON is parsed as YAML,
+// which is its superset.
+func newSecretsFileProvider(path string) (p *secretsFileProvider, err error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	err = yaml.Unmarshal(data, &values)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %q: %w", path, err)
+	}
+
+	return &secretsFileProvider{
+		values: values,
+		path:   path,
+	}, nil
+}
+
+// Name implements the Provider interface for *secretsFileProvider.
+func (p *secretsFileProvider) Name() (name string) { return "secrets file " + p.path }
+
+// Lookup implements the Provider interface for *secretsFileProvider.
+func (p *secretsFileProvider) Lookup(key string) (value string, ok bool, err error) {
+	value, ok = p.values[key]
+
+	return value, ok && value != "", nil
+}
+
+// commandProvider runs the shell command for each secret, e.g. `pass show
+// webext/$GO_WEBEXT_SECRET_KEY`, and uses its output as the value.
+type commandProvider struct {
+	logger  *slog.Logger
+	command string
+	timeout time.Duration
+}
+
+// type check
+var _ Provider = (*commandProvider)(nil)
+
+// Name implements the Provider interface for *commandProvider.
+func (p *commandProvider) Name() (name string) { return "secrets command" }
+
+// Lookup implements the Provider interface for *commandProvider.  The key is
+// considered missing if the command exits with CommandNotFoundExitCode or
+// prints nothing and exits successfully.  Any other failure or a timeout is an
+// error, so that a broken secret manager isn't mistaken for missing
+// credentials.
+func (p *commandProvider) Lookup(key string) (value string, ok bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", p.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", p.command)
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Env = append(os.Environ(), CommandKeyEnv+"="+key)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Don't wait for the children of the shell keeping the output open after
+	// it's killed.
+	cmd.WaitDelay = time.Second
+
+	p.logger.Debug("running secrets command", "key", key)
+
+	err = cmd.Run()
+	exitErr := &exec.ExitError{}
+	if ctx.Err() != nil {
+		return "", false, fmt.Errorf("secrets command timed out after %s", p.timeout)
+	} else if errors.As(err, &exitErr) && exitErr.ExitCode() == CommandNotFoundExitCode {
+		p.logger.Debug("secret not found by secrets command", "key", key)
+
+		return "", false, nil
+	} else if err != nil {
+		return "", false, fmt.Errorf("secrets command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	value = strings.TrimRight(stdout.String(), "\r\n")
+
+	return value, value != "", nil
+}
+
+// profileProvider looks up the credentials in the values of the selected
+// profile and expands the ${VAR} references in them using base.
+type profileProvider struct {
+	values map[string]string
+	base   *Resolver
+}
+
+// type check
+var _ Provider = (*profileProvider)(nil)
+
+// Name implements the Provider interface for *profileProvider.
+func (p *profileProvider) Name() (name string) { return "profile" }
+
+// Lookup implements the Provider interface for *profileProvider.  A reference
+// to a missing variable is an error, since it's likely a misconfiguration.
+func (p *profileProvider) Lookup(key string) (value string, ok bool, err error) {
+	raw, ok := p.values[key]
+	if !ok {
+		return "", false, nil
+	}
+
+	var errs []error
+	value = os.Expand(raw, func(ref string) (v string) {
+		v, found, lookupErr := p.base.LookupSecret(ref)
+		if lookupErr != nil {
+			errs = append(errs, lookupErr)
+		} else if !found {
+			errs = append(errs, fmt.Errorf("variable %s referenced by %s is not set", ref, key))
+		}
+
+		return v
+	})
+
+	err = errors.Join(errs...)
+	if err != nil {
+		return "", false, err
+	}
+
+	return value, value != "", nil
+}
+
+// has returns true if the profile sets the key, possibly to an empty value.
+func (p *profileProvider) has(key string) (ok bool) {
+	_, ok = p.values[key]
+
+	return ok
+}
+
+// covers returns true if the profile sets any value for the store of the key,
+// i.e. with the same prefix before the first underscore, e.g. CHROME.
+func (p *profileProvider) covers(key string) (ok bool) {
+	store, _, _ := strings.Cut(key, "_")
+	for k := range p.values {
+		if s, _, _ := strings.Cut(k, "_"); s == store {
+			return true
+		}
+	}
+
+	return false
+}
@@ -16,10 +16,14 @@ import (
 
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/adguardteam/go-webext/internal/tokencache"
 )
 
 const requestTimeout = 30 * time.Second
 
+// maxErrorBodySize limits the size of the response body included in errors.
+const maxErrorBodySize = 4096
+
 // API versions
 const (
 	// APIVersionV1 represents the v1 API version (default)
@@ -38,6 +42,7 @@ type V1Config struct {
 	clientID       string
 	clientSecret   string
 	accessTokenURL *url.URL
+	tokens         *tokencache.Cache
 }
 
 // NewV1Config creates a new V1Config with the specified parameters.
@@ -46,6 +51,7 @@ func NewV1Config(clientID, clientSecret string, accessTokenURL *url.URL) *V1Conf
 		clientID:       clientID,
 		clientSecret:   clientSecret,
 		accessTokenURL: accessTokenURL,
+		tokens:         tokencache.New(nil),
 	}
 }
 
@@ -73,8 +79,15 @@ func (c *V1Config) SetRequestHeaders(req *http.Request) error {
 	return nil
 }
 
-// Authorize performs the authorization for v1 API and returns an access token.
-func (c *V1Config) authorize() (string, error) {
+// authorize returns the access token for v1 API.  The token is cached until
+// it's about to expire.  It's safe for concurrent use.
+func (c *V1Config) authorize() (accessToken string, err error) {
+	return c.tokens.Token(c.requestAccessToken)
+}
+
+// requestAccessToken performs the authorization for v1 API and returns a new
+// access token with its lifetime.
+func (c *V1Config) requestAccessToken() (accessToken string, expiresIn time.Duration, err error) {
 	form := url.Values{
 		"client_id":     {c.clientID},
 		"scope":         {"https://api.addons.microsoftedge.microsoft.com/.default"},
@@ -84,7 +97,7 @@ func (c *V1Config) authorize() (string, error) {
 
 	req, err := http.NewRequest(http.MethodPost, c.accessTokenURL.String(), strings.NewReader(form.Encode()))
 	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
+		return "", 0, fmt.Errorf("creating request: %w", err)
 	}
 
 	req.Header.Add(httphdr.ContentType, "application/x-www-form-urlencoded")
@@ -93,23 +106,29 @@ func (c *V1Config) authorize() (string, error) {
 
 	res, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("sending request: %w", err)
+		return "", 0, fmt.Errorf("sending request: %w", err)
 	}
 	defer func() { err = errors.WithDeferred(err, res.Body.Close()) }()
 
 	responseBody, err := io.ReadAll(res.Body)
 	if err != nil {
-		return "", fmt.Errorf("reading response: %w", err)
+		return "", 0, fmt.Errorf("reading response: %w", err)
 	}
 
 	var authorizeResponse AuthorizeResponse
 
 	err = json.Unmarshal(responseBody, &authorizeResponse)
 	if err != nil {
-		return "", fmt.Errorf("can't unmarshal response: %s, error: %w", responseBody, err)
+		return "", 0, fmt.Errorf("can't unmarshal response: %s, error: %w", responseBody, err)
 	}
 
-	return authorizeResponse.AccessToken, nil
+	if authorizeResponse.AccessToken == "" {
+		return "", 0, fmt.Errorf("no access token in response with code %d: %s", res.StatusCode, responseBody)
+	}
+
+	expiresIn = time.Duration(authorizeResponse.ExpiresIn) * time.Second
+
+	return authorizeResponse.AccessToken, expiresIn, nil
 }
 
 // SetRequestHeaders sets the authorization headers for the request using v1.1 API configuration.
@@ -521,6 +540,73 @@ func (s Store) Publish(appID string) (response *PublishStatusResponse, err error
 	return s.PublishStatus(appID, operationID)
 }
 
+// ErrUnauthorized is returned by CheckAccess when the store rejects the
+// credentials.
+const ErrUnauthorized errors.Error = "credentials rejected by the store"
+
+// checkOperationID is the ID of a nonexistent operation requested by
+// CheckAccess.
+const checkOperationID = "00000000-0000-0000-0000-000000000000"
+
+// Authorize checks that the request headers can be set, i.e. obtains the
+// access token for v1 API.  It's a no-op for v1.1 API, which uses API keys.
+func (s Store) Authorize() (err error) {
+	req, err := http.NewRequest(http.MethodGet, s.url.String(), nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	return s.client.setRequestHeaders(req)
+}
+
+// CheckAccess performs a harmless read, requesting the status of a
+// nonexistent upload operation of the product, to check that the credentials
+// are accepted.  It returns an error wrapping ErrUnauthorized if they aren't
+// and an error for any other response except the not found one.
+func (s Store) CheckAccess(appID string) (err error) {
+	l := s.logger.With("action", "CheckAccess", "app_id", appID)
+	l.Debug("checking access")
+
+	apiURL := s.url.JoinPath("v1/products", appID, "submissions/draft/package/operations", checkOperationID).String()
+
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	err = s.client.setRequestHeaders(req)
+	if err != nil {
+		return err
+	}
+
+	client := http.Client{
+		Timeout: requestTimeout,
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, res.Body.Close()) }()
+
+	// The operation doesn't exist, so only its not found response means that
+	// the access to the product is granted.
+	switch res.StatusCode {
+	case http.StatusNotFound:
+		l.Debug("access granted", "status_code", res.StatusCode)
+
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+
+		return fmt.Errorf("%w: got code %d, body: %q", ErrUnauthorized, res.StatusCode, body)
+	default:
+		body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+
+		return fmt.Errorf("got code %d, body: %q", res.StatusCode, body)
+	}
+}
+
 // AuthorizeResponse describes the response received from the Edge Store
 // authorization request.
 type AuthorizeResponse struct {
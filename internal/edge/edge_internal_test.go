@@ -52,3 +52,36 @@ func TestAuthorize(t *testing.T) {
 
 	assert.Equal(t, "Bearer "+accessToken, req.Header.Get(httphdr.Authorization))
 }
+
+func TestAuthorize_cache(t *testing.T) {
+	requests := 0
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+
+		response := errors.Must(json.Marshal(AuthorizeResponse{
+			TokenType:   "Bearer",
+			ExpiresIn:   3600,
+			AccessToken: "test_access_token",
+		}))
+
+		_ = errors.Must(w.Write(response))
+	}))
+	defer authServer.Close()
+
+	accessTokenURL, err := url.Parse(authServer.URL)
+	require.NoError(t, err)
+
+	client := NewClient(NewV1Config("test_client_id", "test_client_secret", accessTokenURL))
+
+	for range 3 {
+		req, reqErr := http.NewRequest(http.MethodGet, "http://test.com", nil)
+		require.NoError(t, reqErr)
+
+		err = client.setRequestHeaders(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Bearer test_access_token", req.Header.Get(httphdr.Authorization))
+	}
+
+	assert.Equal(t, 1, requests)
+}
@@ -399,3 +399,68 @@ func TestPublishStatus(t *testing.T) {
 
 	assert.Equal(t, statusResponse, *response)
 }
+
+// errAny is a sentinel for the test cases expecting an error other than
+// edge.ErrUnauthorized.
+const errAny errors.Error = "any error"
+
+func TestCheckAccess(t *testing.T) {
+	testCases := []struct {
+		name       string
+		wantErr    error
+		statusCode int
+	}{{
+		name:       "granted",
+		wantErr:    nil,
+		statusCode: http.StatusNotFound,
+	}, {
+		name:       "rejected",
+		wantErr:    edge.ErrUnauthorized,
+		statusCode: http.StatusUnauthorized,
+	}, {
+		name:       "forbidden",
+		wantErr:    edge.ErrUnauthorized,
+		statusCode: http.StatusForbidden,
+	}, {
+		name:       "bad_request",
+		wantErr:    errAny,
+		statusCode: http.StatusBadRequest,
+	}, {
+		name:       "server_error",
+		wantErr:    errAny,
+		statusCode: http.StatusInternalServerError,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			storeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodGet, r.Method)
+				assert.Equal(t, "ApiKey test_api_key", r.Header.Get(httphdr.Authorization))
+				assert.True(t, strings.HasPrefix(r.URL.Path, path.Join("/v1/products", appID)))
+
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer storeServer.Close()
+
+			storeURL, err := url.Parse(storeServer.URL)
+			require.NoError(t, err)
+
+			store := edge.NewStore(edge.StoreConfig{
+				Client: edge.NewClient(edge.NewV1_1Config(clientID, "test_api_key")),
+				URL:    storeURL,
+				Logger: slogutil.NewDiscardLogger(),
+			})
+
+			err = store.CheckAccess(appID)
+			switch tc.wantErr {
+			case nil:
+				assert.NoError(t, err)
+			case errAny:
+				assert.Error(t, err)
+				assert.NotErrorIs(t, err, edge.ErrUnauthorized)
+			default:
+				assert.ErrorIs(t, err, tc.wantErr)
+			}
+		})
+	}
+}
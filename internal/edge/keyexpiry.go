@@ -0,0 +1,99 @@
+package edge
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// APIKeyLifetime is the lifetime of the v1.1 API keys issued by Partner
+// Center.
+const APIKeyLifetime = 72 * 24 * time.Hour
+
+// DefaultAPIKeyWarnWindow is the default time before the expiry of the API key
+// when the warnings start.
+const DefaultAPIKeyWarnWindow = 14 * 24 * time.Hour
+
+const (
+	// ErrAPIKeyExpiring is returned when the API key expires within the warn
+	// window.
+	ErrAPIKeyExpiring errors.Error = "edge api key expires soon"
+
+	// ErrAPIKeyExpired is returned when the API key has already expired.
+	ErrAPIKeyExpired errors.Error = "edge api key has expired"
+)
+
+// keyDateLayouts are the accepted layouts of the issue and expiry dates.
+var keyDateLayouts = []string{time.DateOnly, time.RFC3339}
+
+// ParseKeyDate parses the issue or expiry date of the API key, either as
+// "2006-01-02" or in RFC 3339 format.  Dates without time are in UTC.
+func ParseKeyDate(s string) (t time.Time, err error) {
+	for _, layout := range keyDateLayouts {
+		t, err = time.Parse(layout, s)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("date %q must be in YYYY-MM-DD or RFC 3339 format", s)
+}
+
+// APIKeyExpiry tracks the expiry of the API key, so that its rotation is
+// scheduled in advance.
+type APIKeyExpiry struct {
+	// ExpiresAt is the expiry time of the key.
+	ExpiresAt time.Time
+
+	// WarnWindow is the time before ExpiresAt when the key is considered
+	// expiring.
+	WarnWindow time.Duration
+}
+
+// NewAPIKeyExpiry returns the expiry of the API key issued at issuedAt or
+// expiring at expiresAt, which takes precedence.  If warnWindow is zero,
+// DefaultAPIKeyWarnWindow is used.  It returns nil if both dates are zero.
+func NewAPIKeyExpiry(issuedAt, expiresAt time.Time, warnWindow time.Duration) (e *APIKeyExpiry) {
+	if expiresAt.IsZero() {
+		if issuedAt.IsZero() {
+			return nil
+		}
+
+		expiresAt = issuedAt.Add(APIKeyLifetime)
+	}
+
+	if warnWindow <= 0 {
+		warnWindow = DefaultAPIKeyWarnWindow
+	}
+
+	return &APIKeyExpiry{
+		ExpiresAt:  expiresAt,
+		WarnWindow: warnWindow,
+	}
+}
+
+// Check returns the time left until the expiry of the key at now.  It returns
+// an error wrapping ErrAPIKeyExpired if the key has expired or
+// ErrAPIKeyExpiring if it expires within the warn window.
+func (e *APIKeyExpiry) Check(now time.Time) (left time.Duration, err error) {
+	left = e.ExpiresAt.Sub(now)
+	expires := e.ExpiresAt.Format(time.DateOnly)
+
+	switch {
+	case left <= 0:
+		return left, fmt.Errorf("%w on %s, create a new one in Partner Center", ErrAPIKeyExpired, expires)
+	case left <= e.WarnWindow:
+		return left, fmt.Errorf("%w: on %s, in %d days", ErrAPIKeyExpiring, expires, DaysLeft(left))
+	default:
+		return left, nil
+	}
+}
+
+// DaysLeft returns the number of whole days in left, rounded up, so that a key
+// expiring later today has one day left.
+func DaysLeft(left time.Duration) (days int) {
+	const day = 24 * time.Hour
+
+	return int((left + day - 1) / day)
+}
@@ -0,0 +1,72 @@
+package edge_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adguardteam/go-webext/internal/edge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeyDate(t *testing.T) {
+	got, err := edge.ParseKeyDate("2026-10-01")
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), got)
+
+	got, err = edge.ParseKeyDate("2026-10-01T12:00:00+02:00")
+	require.NoError(t, err)
+
+	assert.True(t, got.Equal(time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)))
+
+	_, err = edge.ParseKeyDate("01.10.2026")
+	assert.Error(t, err)
+}
+
+func TestAPIKeyExpiry_Check(t *testing.T) {
+	issuedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, edge.NewAPIKeyExpiry(time.Time{}, time.Time{}, 0))
+
+	expiry := edge.NewAPIKeyExpiry(issuedAt, time.Time{}, 0)
+	require.NotNil(t, expiry)
+
+	assert.Equal(t, issuedAt.Add(72*24*time.Hour), expiry.ExpiresAt)
+	assert.Equal(t, edge.DefaultAPIKeyWarnWindow, expiry.WarnWindow)
+
+	testCases := []struct {
+		now      time.Time
+		wantErr  error
+		name     string
+		wantDays int
+	}{{
+		now:      issuedAt.Add(24 * time.Hour),
+		wantErr:  nil,
+		name:     "fresh",
+		wantDays: 71,
+	}, {
+		now:      issuedAt.Add(60 * 24 * time.Hour),
+		wantErr:  edge.ErrAPIKeyExpiring,
+		name:     "expiring",
+		wantDays: 12,
+	}, {
+		now:      issuedAt.Add(73 * 24 * time.Hour),
+		wantErr:  edge.ErrAPIKeyExpired,
+		name:     "expired",
+		wantDays: 0,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			left, err := expiry.Check(tc.now)
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.wantErr)
+			}
+
+			assert.Equal(t, tc.wantDays, edge.DaysLeft(left))
+		})
+	}
+}
@@ -0,0 +1,10 @@
+// Package extstore contains the names of the extension stores shared by the
+// profiles, the package builder and the linter.
+package extstore
+
+// Names of the stores.
+const (
+	Chrome  = "chrome"
+	Firefox = "firefox"
+	Edge    = "edge"
+)
@@ -0,0 +1,96 @@
+// Package extversion parses and compares extension versions in the
+// dotted-integer format used by Chrome and required by AMO for new versions.
+package extversion
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// MaxParts is the maximum number of dot-separated parts of a version.
+const MaxParts = 4
+
+// MaxChromePart is the maximum value of a version part accepted by Chrome.
+const MaxChromePart = 65535
+
+// ErrInvalid is returned when the version string isn't in the dotted-integer
+// format.
+const ErrInvalid errors.Error = "invalid version"
+
+// Version is a parsed dotted-integer version, e.g. 1.2.3.4.
+type Version []uint64
+
+// Parse parses the version of one to four dot-separated integers without
+// leading zeros.  It returns an error wrapping ErrInvalid if s is malformed.
+func Parse(s string) (v Version, err error) {
+	parts := strings.Split(s, ".")
+	if len(parts) > MaxParts {
+		return nil, fmt.Errorf("%w %q: more than %d parts", ErrInvalid, s, MaxParts)
+	}
+
+	v = make(Version, 0, len(parts))
+	for _, p := range parts {
+		if p == "" || (len(p) > 1 && p[0] == '0') || strings.TrimLeft(p, "0123456789") != "" {
+			return nil, fmt.Errorf("%w %q: part %q must be an integer without leading zeros", ErrInvalid, s, p)
+		}
+
+		var n uint64
+		n, err = strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalid, s, err)
+		}
+
+		v = append(v, n)
+	}
+
+	return v, nil
+}
+
+// CheckChrome returns an error if a part of v exceeds MaxChromePart.
+func (v Version) CheckChrome() (err error) {
+	for _, n := range v {
+		if n > MaxChromePart {
+			return fmt.Errorf("%w %s: part %d exceeds %d", ErrInvalid, v, n, MaxChromePart)
+		}
+	}
+
+	return nil
+}
+
+// String implements the fmt.Stringer interface for Version.
+func (v Version) String() (s string) {
+	parts := make([]string, len(v))
+	for i, n := range v {
+		parts[i] = strconv.FormatUint(n, 10)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// Compare returns -1, 0 or +1 if a is less than, equal to or greater than b.
+// Missing parts are treated as zeros, so 1.2 equals 1.2.0.
+func Compare(a, b Version) (res int) {
+	for i := range max(len(a), len(b)) {
+		x, y := part(a, i), part(b, i)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// part returns the i-th part of v or zero if there is none.
+func part(v Version, i int) (n uint64) {
+	if i < len(v) {
+		return v[i]
+	}
+
+	return 0
+}
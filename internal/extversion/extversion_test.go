@@ -0,0 +1,112 @@
+package extversion_test
+
+import (
+	"testing"
+
+	"github.com/adguardteam/go-webext/internal/extversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		in      string
+		want    extversion.Version
+		wantErr bool
+	}{{
+		in:   "1",
+		want: extversion.Version{1},
+	}, {
+		in:   "1.2.3.4",
+		want: extversion.Version{1, 2, 3, 4},
+	}, {
+		in:   "0.10.0",
+		want: extversion.Version{0, 10, 0},
+	}, {
+		in:      "1.2.3.4.5",
+		wantErr: true,
+	}, {
+		in:      "1.02",
+		wantErr: true,
+	}, {
+		in:      "1..2",
+		wantErr: true,
+	}, {
+		in:      "1.2beta",
+		wantErr: true,
+	}, {
+		in:      "",
+		wantErr: true,
+	}, {
+		in:      "-1",
+		wantErr: true,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			v, err := extversion.Parse(tc.in)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, extversion.ErrInvalid)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want, v)
+			assert.Equal(t, tc.in, v.String())
+		})
+	}
+}
+
+func TestVersion_CheckChrome(t *testing.T) {
+	v, err := extversion.Parse("1.65535")
+	require.NoError(t, err)
+
+	assert.NoError(t, v.CheckChrome())
+
+	v, err = extversion.Parse("1.65536")
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, v.CheckChrome(), extversion.ErrInvalid)
+}
+
+func TestCompare(t *testing.T) {
+	testCases := []struct {
+		a    string
+		b    string
+		want int
+	}{{
+		a:    "1.2.3",
+		b:    "1.2.3",
+		want: 0,
+	}, {
+		a:    "1.2",
+		b:    "1.2.0.0",
+		want: 0,
+	}, {
+		a:    "1.10",
+		b:    "1.9",
+		want: 1,
+	}, {
+		a:    "1.2.3",
+		b:    "1.2.3.1",
+		want: -1,
+	}, {
+		a:    "2",
+		b:    "1.99.99",
+		want: 1,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.a+"_"+tc.b, func(t *testing.T) {
+			a, err := extversion.Parse(tc.a)
+			require.NoError(t, err)
+
+			b, err := extversion.Parse(tc.b)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want, extversion.Compare(a, b))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package extversion
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+)
+
+// ErrNoStoreAPI is returned by IncreaseConfig.StoreVersion for the stores
+// without an API to get the current version, e.g. Edge.
+const ErrNoStoreAPI errors.Error = "store has no api to get the current version"
+
+// ErrNotIncreased is returned by CheckIncrease when the package version isn't
+// greater than the store version.
+const ErrNotIncreased errors.Error = "version not increased"
+
+// IncreaseConfig is the configuration of CheckIncrease.
+type IncreaseConfig struct {
+	// Logger is used to log the skipped checks.
+	Logger *slog.Logger
+
+	// StoreVersion returns the current version of the item in the store.  It
+	// returns an error wrapping ErrNoStoreAPI if the store has no API for it
+	// and an empty string if the item has no version in the store.
+	StoreVersion func() (version string, err error)
+
+	// Version is the version of the package.
+	Version string
+
+	// Force skips the check.
+	Force bool
+}
+
+// CheckIncrease returns an error if the package version isn't greater than the
+// current version of the item in the store, since the stores reject such
+// packages only after the upload.  The check is skipped with a warning if
+// conf.Force is set, the store has no API to get the version or the store
+// version can't be parsed, and skipped if the item has no version in the
+// store.
+func CheckIncrease(conf IncreaseConfig) (err error) {
+	l := conf.Logger
+
+	if conf.Force {
+		l.Warn("skipping version check due to --force")
+
+		return nil
+	}
+
+	current, err := conf.StoreVersion()
+	if errors.Is(err, ErrNoStoreAPI) {
+		l.Warn("skipping version check, the package version isn't compared with the store", slogutil.KeyError, err)
+
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("getting store version: %w, use --force to upload anyway", err)
+	}
+
+	if current == "" {
+		l.Info("skipping version check, no version in the store")
+
+		return nil
+	}
+
+	currentVersion, err := Parse(current)
+	if err != nil {
+		l.Warn("skipping version check, can't parse store version", "version", current, slogutil.KeyError, err)
+
+		return nil
+	}
+
+	pkgVersion, err := Parse(conf.Version)
+	if err != nil {
+		return fmt.Errorf("package version: %w, use --force to upload anyway", err)
+	}
+
+	if Compare(pkgVersion, currentVersion) <= 0 {
+		return fmt.Errorf(
+			"%w: package version %s must be greater than the store version %s, use --force to upload anyway",
+			ErrNotIncreased,
+			conf.Version,
+			current,
+		)
+	}
+
+	l.Debug("version check passed", "package_version", conf.Version, "store_version", current)
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package extversion_test
+
+import (
+	"testing"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/adguardteam/go-webext/internal/extversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckIncrease(t *testing.T) {
+	const errTest errors.Error = "test error"
+
+	testCases := []struct {
+		storeErr     error
+		wantErrIs    error
+		name         string
+		storeVersion string
+		version      string
+		wantErrMsg   string
+		force        bool
+	}{{
+		storeErr:     nil,
+		wantErrIs:    nil,
+		name:         "increased",
+		storeVersion: "1.9",
+		version:      "1.10",
+		wantErrMsg:   "",
+		force:        false,
+	}, {
+		storeErr:     nil,
+		wantErrIs:    extversion.ErrNotIncreased,
+		name:         "equal",
+		storeVersion: "1.2",
+		version:      "1.2.0",
+		wantErrMsg: "version not increased: package version 1.2.0 must be greater than " +
+			"the store version 1.2, use --force to upload anyway",
+		force: false,
+	}, {
+		storeErr:     nil,
+		wantErrIs:    nil,
+		name:         "force",
+		storeVersion: "2.0",
+		version:      "1.0",
+		wantErrMsg:   "",
+		force:        true,
+	}, {
+		storeErr:     nil,
+		wantErrIs:    nil,
+		name:         "empty_store_version",
+		storeVersion: "",
+		version:      "1.0",
+		wantErrMsg:   "",
+		force:        false,
+	}, {
+		storeErr:     nil,
+		wantErrIs:    nil,
+		name:         "unparsable_store_version",
+		storeVersion: "2.0beta",
+		version:      "1.0",
+		wantErrMsg:   "",
+		force:        false,
+	}, {
+		storeErr:     nil,
+		wantErrIs:    extversion.ErrInvalid,
+		name:         "unparsable_package_version",
+		storeVersion: "1.0",
+		version:      "1.02",
+		wantErrMsg: `package version: invalid version "1.02": part "02" must be an integer ` +
+			"without leading zeros, use --force to upload anyway",
+		force: false,
+	}, {
+		storeErr:     extversion.ErrNoStoreAPI,
+		wantErrIs:    nil,
+		name:         "no_store_api",
+		storeVersion: "",
+		version:      "1.0",
+		wantErrMsg:   "",
+		force:        false,
+	}, {
+		storeErr:     errTest,
+		wantErrIs:    errTest,
+		name:         "store_error",
+		storeVersion: "",
+		version:      "1.0",
+		wantErrMsg:   "getting store version: test error, use --force to upload anyway",
+		force:        false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := extversion.CheckIncrease(extversion.IncreaseConfig{
+				Logger: slogutil.NewDiscardLogger(),
+				StoreVersion: func() (version string, err error) {
+					require.False(t, tc.force, "store version requested with --force")
+
+					return tc.storeVersion, tc.storeErr
+				},
+				Version: tc.version,
+				Force:   tc.force,
+			})
+			if tc.wantErrMsg == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.EqualError(t, err, tc.wantErrMsg)
+			assert.ErrorIs(t, err, tc.wantErrIs)
+		})
+	}
+}
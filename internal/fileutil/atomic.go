@@ -0,0 +1,71 @@
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// WriteFileAtomic creates a temporary file in the directory of path, passes it
+// to write and renames it to path with permissions perm if write succeeds.  The
+// temporary file is removed on any error, so that path never contains a
+// partially written file.
+func WriteFileAtomic(path string, perm os.FileMode, write func(f *os.File) (err error)) (err error) {
+	path = filepath.Clean(path)
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	f, err := os.CreateTemp(dir, "."+base+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %w", err)
+	}
+
+	tmpPath := f.Name()
+	defer func() {
+		if err != nil {
+			err = errors.WithDeferred(err, removeIfExists(tmpPath))
+		}
+	}()
+
+	err = write(f)
+	if err != nil {
+		return errors.WithDeferred(err, f.Close())
+	}
+
+	err = f.Chmod(perm)
+	if err != nil {
+		return errors.WithDeferred(fmt.Errorf("changing permissions: %w", err), f.Close())
+	}
+
+	err = f.Sync()
+	if err != nil {
+		return errors.WithDeferred(fmt.Errorf("syncing temporary file: %w", err), f.Close())
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("closing temporary file: %w", err)
+	}
+
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		return fmt.Errorf("renaming temporary file: %w", err)
+	}
+
+	return nil
+}
+
+// removeIfExists removes the file, it doesn't return an error if the file
+// doesn't exist.
+func removeIfExists(path string) (err error) {
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing temporary file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package fileutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// crxMagic is the magic number at the start of the CRX files.
+var crxMagic = []byte("Cr24")
+
+// ZipArchive is a zip archive opened by OpenZip.
+type ZipArchive struct {
+	*zip.Reader
+
+	file *os.File
+}
+
+// type check
+var _ io.Closer = (*ZipArchive)(nil)
+
+// Close implements the io.Closer interface for *ZipArchive.
+func (a *ZipArchive) Close() (err error) {
+	return a.file.Close()
+}
+
+// OpenZip opens the zip archive at path, which may also be a CRX file, i.e.
+// a zip archive prefixed with the CRX2 or CRX3 header.
+func OpenZip(path string) (a *ZipArchive, err error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = errors.WithDeferred(err, f.Close())
+		}
+	}()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("getting file info: %w", err)
+	}
+
+	offset, err := crxHeaderSize(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading crx header: %w", err)
+	}
+
+	size := fi.Size() - offset
+	if size < 0 {
+		return nil, errors.Error("crx header exceeds the file size")
+	}
+
+	r, err := zip.NewReader(io.NewSectionReader(f, offset, size), size)
+	if err != nil {
+		return nil, fmt.Errorf("opening zip file: %w", err)
+	}
+
+	return &ZipArchive{Reader: r, file: f}, nil
+}
+
+// crxHeaderSize returns the size of the CRX header at the start of r or zero
+// if r doesn't start with it.  See
+// https://chromium.googlesource.com/chromium/src/+/main/components/crx_file/crx3.proto.
+func crxHeaderSize(r io.ReaderAt) (size int64, err error) {
+	// Magic number and version, followed by the header length in CRX3 or by
+	// the public key and signature lengths in CRX2.
+	var head [16]byte
+	n, err := r.ReadAt(head[:], 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+
+	if n < len(head) || !bytes.Equal(head[:4], crxMagic) {
+		return 0, nil
+	}
+
+	version := binary.LittleEndian.Uint32(head[4:8])
+	switch version {
+	case 2:
+		keyLen := binary.LittleEndian.Uint32(head[8:12])
+		sigLen := binary.LittleEndian.Uint32(head[12:16])
+
+		return 16 + int64(keyLen) + int64(sigLen), nil
+	case 3:
+		headerLen := binary.LittleEndian.Uint32(head[8:12])
+
+		return 12 + int64(headerLen), nil
+	default:
+		return 0, fmt.Errorf("unsupported crx version %d", version)
+	}
+}
@@ -0,0 +1,65 @@
+package fileutil
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// SetEnvFileValue sets key to value in the dotenv file at path.  The line
+// assigning the key, with or without "export", is replaced in place, otherwise
+// the assignment is appended.  Other lines, including comments, are preserved.
+// The file is created if it doesn't exist and is written atomically with
+// permissions readable only by the owner, since it usually contains secrets.
+func SetEnvFileValue(path, key, value string) (err error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading env file: %w", err)
+	}
+
+	assignment := key + "=" + quoteEnvValue(value)
+
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+
+	i := slices.IndexFunc(lines, func(line string) (ok bool) {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "export ")
+
+		return strings.HasPrefix(strings.TrimSpace(line), key+"=")
+	})
+	if i >= 0 {
+		lines[i] = assignment
+	} else {
+		lines = append(lines, assignment)
+	}
+
+	buf := &bytes.Buffer{}
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+
+	return WriteFileAtomic(path, 0o600, func(f *os.File) (err error) {
+		_, err = f.Write(buf.Bytes())
+
+		return err
+	})
+}
+
+// quoteEnvValue quotes value if it contains characters with a special meaning
+// in dotenv files.
+func quoteEnvValue(value string) (quoted string) {
+	if value == "" || strings.ContainsAny(value, " \t\n#'\"\\$") {
+		return strconv.Quote(value)
+	}
+
+	return value
+}
@@ -0,0 +1,48 @@
+package fileutil_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adguardteam/go-webext/internal/fileutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetEnvFileValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+
+	t.Run("create", func(t *testing.T) {
+		err := fileutil.SetEnvFileValue(path, "CHROME_REFRESH_TOKEN", "1//token")
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		assert.Equal(t, "CHROME_REFRESH_TOKEN=1//token\n", string(data))
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		err := os.WriteFile(path, []byte("# chrome\nCHROME_CLIENT_ID=id\nexport CHROME_REFRESH_TOKEN=old\n"), 0o600)
+		require.NoError(t, err)
+
+		err = fileutil.SetEnvFileValue(path, "CHROME_REFRESH_TOKEN", "new token")
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		assert.Equal(t, "# chrome\nCHROME_CLIENT_ID=id\nCHROME_REFRESH_TOKEN=\"new token\"\n", string(data))
+	})
+
+	t.Run("append", func(t *testing.T) {
+		err := fileutil.SetEnvFileValue(path, "CHROME_PUBLISHER_ID", "publisher")
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(data), "CHROME_REFRESH_TOKEN=\"new token\"\nCHROME_PUBLISHER_ID=publisher\n")
+	})
+}
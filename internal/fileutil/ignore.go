@@ -0,0 +1,192 @@
+package fileutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// IgnoreFilename is the name of the files with ignore patterns.
+const IgnoreFilename = ".gitignore"
+
+// ignoreRule is a single compiled pattern of the ignore file.
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// Ignore matches slash-separated paths relative to the root directory against
+// patterns in the .gitignore format.  The zero value matches nothing.
+type Ignore struct {
+	rules []ignoreRule
+}
+
+// Add adds the pattern relative to the directory base, which is a
+// slash-separated path relative to the root directory or an empty string for
+// the root itself.  Blank lines, comments and invalid patterns are ignored.
+func (ig *Ignore) Add(base, pattern string) {
+	pattern = strings.TrimRight(pattern, " \t\r")
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return
+	}
+
+	r := ignoreRule{}
+	if strings.HasPrefix(pattern, "!") {
+		r.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\`) {
+		pattern = pattern[1:]
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		r.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+
+	if pattern == "" {
+		return
+	}
+
+	// Patterns with a slash in the beginning or the middle are relative to
+	// base, others match at any level below it.
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	prefix := "^"
+	if base != "" {
+		prefix += regexp.QuoteMeta(strings.Trim(base, "/")) + "/"
+	}
+
+	if !anchored {
+		prefix += "(?:.*/)?"
+	}
+
+	re, err := regexp.Compile(prefix + globToRegexp(pattern) + "$")
+	if err != nil {
+		// Invalid patterns, e.g. with malformed character classes, are
+		// skipped the same way git does.
+		return
+	}
+
+	r.re = re
+	ig.rules = append(ig.rules, r)
+}
+
+// AddFile adds patterns from the ignore file at filePath, which are relative
+// to the directory base.  It's not an error if the file doesn't exist.
+func (ig *Ignore) AddFile(base, filePath string) (err error) {
+	f, err := os.Open(filepath.Clean(filePath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("opening ignore file: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, f.Close()) }()
+
+	return ig.addFrom(base, f)
+}
+
+// addFrom adds patterns from r line by line.
+func (ig *Ignore) addFrom(base string, r io.Reader) (err error) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		ig.Add(base, s.Text())
+	}
+
+	err = s.Err()
+	if err != nil {
+		return fmt.Errorf("reading ignore file: %w", err)
+	}
+
+	return nil
+}
+
+// Match returns true if the slash-separated path relative to the root
+// directory matches the patterns, either itself or through one of its parent
+// directories.  isDir must be true if the path is a directory.
+func (ig *Ignore) Match(relPath string, isDir bool) (ok bool) {
+	if ig == nil || len(ig.rules) == 0 {
+		return false
+	}
+
+	relPath = strings.Trim(relPath, "/")
+
+	// As in git, a file can't be re-included if its parent directory is
+	// excluded.
+	for i := range len(relPath) {
+		if relPath[i] == '/' && ig.match(relPath[:i], true) {
+			return true
+		}
+	}
+
+	return ig.match(relPath, isDir)
+}
+
+// match returns the result of the last rule matching relPath.
+func (ig *Ignore) match(relPath string, isDir bool) (ok bool) {
+	for _, r := range ig.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+
+		if r.re.MatchString(relPath) {
+			ok = !r.negate
+		}
+	}
+
+	return ok
+}
+
+// globToRegexp converts the glob pattern of the ignore file to the regular
+// expression.
+func globToRegexp(pattern string) (re string) {
+	b := &strings.Builder{}
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "/**") && i+3 == len(pattern):
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(regexp.QuoteMeta(string(c)))
+
+				continue
+			}
+
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return b.String()
+}
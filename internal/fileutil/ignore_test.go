@@ -0,0 +1,91 @@
+package fileutil_test
+
+import (
+	"testing"
+
+	"github.com/adguardteam/go-webext/internal/fileutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnore_Match(t *testing.T) {
+	ig := &fileutil.Ignore{}
+	for _, p := range []string{
+		"# comment",
+		"",
+		"node_modules/",
+		"*.log",
+		"!keep.log",
+		"/build",
+		"docs/**/*.tmp",
+		"secret?.txt",
+	} {
+		ig.Add("", p)
+	}
+	ig.Add("src", "generated/")
+
+	testCases := []struct {
+		name  string
+		path  string
+		isDir bool
+		want  bool
+	}{{
+		name: "dir_only_pattern_file",
+		path: "node_modules",
+		want: false,
+	}, {
+		name:  "dir_only_pattern_dir",
+		path:  "lib/node_modules",
+		isDir: true,
+		want:  true,
+	}, {
+		name: "inside_ignored_dir",
+		path: "node_modules/pkg/index.js",
+		want: true,
+	}, {
+		name: "extension",
+		path: "logs/debug.log",
+		want: true,
+	}, {
+		name: "negated",
+		path: "keep.log",
+		want: false,
+	}, {
+		name: "anchored",
+		path: "build/out.js",
+		want: true,
+	}, {
+		name: "anchored_nested",
+		path: "src/build/out.js",
+		want: false,
+	}, {
+		name: "double_star",
+		path: "docs/a/b/c.tmp",
+		want: true,
+	}, {
+		name: "double_star_zero_dirs",
+		path: "docs/c.tmp",
+		want: true,
+	}, {
+		name: "question_mark",
+		path: "secret1.txt",
+		want: true,
+	}, {
+		name: "nested_base",
+		path: "src/generated/a.js",
+		want: true,
+	}, {
+		name: "nested_base_other_dir",
+		path: "generated/a.js",
+		want: false,
+	}, {
+		name: "not_matched",
+		path: "src/index.js",
+		want: false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ig.Match(tc.path, tc.isDir))
+		})
+	}
+}
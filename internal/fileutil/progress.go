@@ -0,0 +1,72 @@
+package fileutil
+
+import (
+	"io"
+	"log/slog"
+	"time"
+)
+
+// progressLogInterval is the minimum interval between two progress messages.
+const progressLogInterval = 5 * time.Second
+
+// ProgressReader is an io.Reader that logs the number of bytes read from the
+// underlying reader.
+type ProgressReader struct {
+	reader  io.Reader
+	logger  *slog.Logger
+	lastLog time.Time
+	total   int64
+	read    int64
+}
+
+// type check
+var _ io.Reader = (*ProgressReader)(nil)
+
+// NewProgressReader returns a new *ProgressReader reading from r.  total is
+// the expected number of bytes, it's -1 if unknown.  Messages are logged with
+// l at most once per five seconds and at the end of the stream.
+func NewProgressReader(r io.Reader, total int64, l *slog.Logger) (pr *ProgressReader) {
+	return &ProgressReader{
+		reader:  r,
+		logger:  l,
+		lastLog: time.Now(),
+		total:   total,
+	}
+}
+
+// Read implements the io.Reader interface for *ProgressReader.
+func (pr *ProgressReader) Read(p []byte) (n int, err error) {
+	n, err = pr.reader.Read(p)
+	pr.read += int64(n)
+
+	if err == io.EOF {
+		pr.log()
+	} else if time.Since(pr.lastLog) >= progressLogInterval {
+		pr.log()
+	}
+
+	return n, err
+}
+
+// log logs the current progress.
+func (pr *ProgressReader) log() {
+	pr.lastLog = time.Now()
+
+	if pr.total > 0 {
+		pr.logger.Info(
+			"transfer progress",
+			"bytes", pr.read,
+			"total", pr.total,
+			"percent", pr.read*100/pr.total,
+		)
+
+		return
+	}
+
+	pr.logger.Info("transfer progress", "bytes", pr.read)
+}
+
+// BytesRead returns the number of bytes read so far.
+func (pr *ProgressReader) BytesRead() (n int64) {
+	return pr.read
+}
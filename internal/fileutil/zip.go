@@ -0,0 +1,127 @@
+package fileutil
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// ZipModTime is the modification time of all files written by WriteZip.  It's
+// the earliest time the zip format can represent.
+var ZipModTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// ZipFileMode is the permissions of the files written by WriteZip.
+const ZipFileMode os.FileMode = 0o644
+
+// ZipExecFileMode is the permissions of the files written by WriteZip which
+// have any executable bit set, e.g. the build scripts in the source archives.
+const ZipExecFileMode os.FileMode = 0o755
+
+// ZipCompressionLevel is the deflate level of all files written by WriteZip.
+const ZipCompressionLevel = flate.BestCompression
+
+// ZipEntry is a file to be written to the zip archive.
+type ZipEntry struct {
+	// Name is the slash-separated name of the file in the archive.
+	Name string
+	// Path is the path of the file on disk, it's used if Data is nil.
+	Path string
+	// Data is the content of the file.
+	Data []byte
+	// Mode is the mode of the file with Data, only its executable bits are
+	// used.  The mode of the file at Path is used otherwise.
+	Mode os.FileMode
+}
+
+// WriteZip writes entries to w as a reproducible zip archive: the entries are
+// sorted by name and written with ZipModTime, ZipCompressionLevel and
+// ZipExecFileMode for the executable files or ZipFileMode for others, so that
+// the archive only depends on the names, the contents and the executability of
+// the files.
+func WriteZip(w io.Writer, entries []ZipEntry) (err error) {
+	zw := zip.NewWriter(w)
+	zw.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(out, ZipCompressionLevel)
+	})
+
+	sorted := slices.Clone(entries)
+	slices.SortStableFunc(sorted, func(a, b ZipEntry) int { return strings.Compare(a.Name, b.Name) })
+
+	for _, e := range sorted {
+		err = writeZipEntry(zw, e)
+		if err != nil {
+			return errors.WithDeferred(fmt.Errorf("adding %q: %w", e.Name, err), zw.Close())
+		}
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return fmt.Errorf("closing zip writer: %w", err)
+	}
+
+	return nil
+}
+
+// writeZipEntry writes a single entry to zw.
+func writeZipEntry(zw *zip.Writer, e ZipEntry) (err error) {
+	var f *os.File
+	mode := e.Mode
+	if e.Data == nil {
+		f, err = os.Open(filepath.Clean(e.Path))
+		if err != nil {
+			return fmt.Errorf("opening file: %w", err)
+		}
+		defer func() { err = errors.WithDeferred(err, f.Close()) }()
+
+		var fi os.FileInfo
+		fi, err = f.Stat()
+		if err != nil {
+			return fmt.Errorf("getting file info: %w", err)
+		}
+
+		mode = fi.Mode()
+	}
+
+	header := &zip.FileHeader{
+		Name:     e.Name,
+		Method:   zip.Deflate,
+		Modified: ZipModTime,
+	}
+	header.SetMode(zipFileMode(mode))
+
+	fw, err := zw.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("creating header: %w", err)
+	}
+
+	if f == nil {
+		_, err = fw.Write(e.Data)
+
+		return err
+	}
+
+	_, err = io.Copy(fw, f)
+	if err != nil {
+		return fmt.Errorf("copying file: %w", err)
+	}
+
+	return nil
+}
+
+// zipFileMode returns the normalized permissions of the file with the mode:
+// ZipExecFileMode if any executable bit is set and ZipFileMode otherwise.
+func zipFileMode(mode os.FileMode) (normalized os.FileMode) {
+	if mode&0o111 != 0 {
+		return ZipExecFileMode
+	}
+
+	return ZipFileMode
+}
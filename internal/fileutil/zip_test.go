@@ -0,0 +1,269 @@
+package fileutil_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/adguardteam/go-webext/internal/fileutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestZip writes the entries with WriteZip to a temporary file and
+// returns its path.
+func writeTestZip(t *testing.T, entries []fileutil.ZipEntry) (zipPath string) {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, fileutil.WriteZip(buf, entries))
+
+	zipPath = filepath.Join(t.TempDir(), "test.zip")
+	require.NoError(t, os.WriteFile(zipPath, buf.Bytes(), 0o600))
+
+	return zipPath
+}
+
+func TestWriteZip_reproducible(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "script.js")
+	require.NoError(t, os.WriteFile(filePath, []byte("console.log(1);"), 0o640))
+
+	entries := []fileutil.ZipEntry{
+		{Name: "script.js", Path: filePath},
+		{Name: "a/b.txt", Data: []byte("b")},
+		{Name: "manifest.json", Data: []byte("{}")},
+	}
+
+	first := writeTestZip(t, entries)
+
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(filePath, later, later))
+	require.NoError(t, os.Chmod(filePath, 0o600))
+
+	reversed := []fileutil.ZipEntry{entries[2], entries[1], entries[0]}
+	second := writeTestZip(t, reversed)
+
+	firstData, err := os.ReadFile(first)
+	require.NoError(t, err)
+
+	secondData, err := os.ReadFile(second)
+	require.NoError(t, err)
+
+	assert.Equal(t, firstData, secondData)
+
+	r, err := zip.OpenReader(first)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, r.Close()) })
+
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+		assert.True(t, f.Modified.Equal(fileutil.ZipModTime), f.Name)
+		assert.Equal(t, fileutil.ZipFileMode, f.Mode(), f.Name)
+	}
+
+	assert.Equal(t, []string{"a/b.txt", "manifest.json", "script.js"}, names)
+}
+
+func TestWriteZip_executable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executable bits aren't supported on windows")
+	}
+
+	dir := t.TempDir()
+
+	buildPath := filepath.Join(dir, "build.sh")
+	require.NoError(t, os.WriteFile(buildPath, []byte("#!/bin/sh"), 0o700))
+
+	readmePath := filepath.Join(dir, "README.md")
+	require.NoError(t, os.WriteFile(readmePath, []byte("# Build"), 0o664))
+
+	zipPath := writeTestZip(t, []fileutil.ZipEntry{
+		{Name: "build.sh", Path: buildPath},
+		{Name: "README.md", Path: readmePath},
+		{Name: "gen.sh", Data: []byte("#!/bin/sh"), Mode: 0o744},
+		{Name: "data.txt", Data: []byte("data")},
+	})
+
+	r, err := zip.OpenReader(zipPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, r.Close()) })
+
+	modes := map[string]os.FileMode{}
+	for _, f := range r.File {
+		modes[f.Name] = f.Mode()
+	}
+
+	assert.Equal(t, map[string]os.FileMode{
+		"README.md": fileutil.ZipFileMode,
+		"build.sh":  fileutil.ZipExecFileMode,
+		"data.txt":  fileutil.ZipFileMode,
+		"gen.sh":    fileutil.ZipExecFileMode,
+	}, modes)
+}
+
+func TestCompareZips(t *testing.T) {
+	base := writeTestZip(t, []fileutil.ZipEntry{
+		{Name: "a.js", Data: []byte("a")},
+		{Name: "b.js", Data: []byte("b")},
+	})
+
+	t.Run("identical", func(t *testing.T) {
+		same := writeTestZip(t, []fileutil.ZipEntry{
+			{Name: "b.js", Data: []byte("b")},
+			{Name: "a.js", Data: []byte("a")},
+		})
+
+		diffs, err := fileutil.CompareZips(base, same)
+		require.NoError(t, err)
+		assert.Empty(t, diffs)
+	})
+
+	t.Run("entries", func(t *testing.T) {
+		other := writeTestZip(t, []fileutil.ZipEntry{
+			{Name: "a.js", Data: []byte("changed")},
+			{Name: "c.js", Data: []byte("c")},
+		})
+
+		diffs, err := fileutil.CompareZips(base, other)
+		require.NoError(t, err)
+
+		var got []fileutil.ZipDiff
+		for _, d := range diffs {
+			got = append(got, fileutil.ZipDiff{Name: d.Name, Kind: d.Kind})
+		}
+
+		assert.Equal(t, []fileutil.ZipDiff{
+			{Name: "a.js", Kind: fileutil.ZipDiffContent},
+			{Name: "b.js", Kind: fileutil.ZipDiffMissing},
+			{Name: "c.js", Kind: fileutil.ZipDiffExtra},
+		}, got)
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		zw := zip.NewWriter(buf)
+		for _, name := range []string{"b.js", "a.js"} {
+			w, err := zw.CreateHeader(&zip.FileHeader{
+				Name:     name,
+				Method:   zip.Store,
+				Modified: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+			})
+			require.NoError(t, err)
+
+			_, err = w.Write([]byte(name[:1]))
+			require.NoError(t, err)
+		}
+		require.NoError(t, zw.Close())
+
+		other := filepath.Join(t.TempDir(), "other.zip")
+		require.NoError(t, os.WriteFile(other, buf.Bytes(), 0o600))
+
+		diffs, err := fileutil.CompareZips(base, other)
+		require.NoError(t, err)
+
+		kinds := map[fileutil.ZipDiffKind]int{}
+		for _, d := range diffs {
+			kinds[d.Kind]++
+		}
+
+		assert.Equal(t, map[fileutil.ZipDiffKind]int{
+			fileutil.ZipDiffModified: 2,
+			fileutil.ZipDiffMode:     2,
+			fileutil.ZipDiffMethod:   2,
+			fileutil.ZipDiffOrder:    1,
+		}, kinds)
+	})
+
+	t.Run("order", func(t *testing.T) {
+		// Neither an added entry nor a removed one moves the others.
+		first := writeTestZip(t, []fileutil.ZipEntry{
+			{Name: "b.js", Data: []byte("b")},
+			{Name: "c.js", Data: []byte("c")},
+			{Name: "d.js", Data: []byte("d")},
+			{Name: "e.js", Data: []byte("e")},
+		})
+
+		buf := &bytes.Buffer{}
+		zw := zip.NewWriter(buf)
+		for _, name := range []string{"a.js", "c.js", "e.js", "d.js"} {
+			w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: fileutil.ZipModTime})
+			require.NoError(t, err)
+
+			_, err = w.Write([]byte(name[:1]))
+			require.NoError(t, err)
+		}
+		require.NoError(t, zw.Close())
+
+		second := filepath.Join(t.TempDir(), "second.zip")
+		require.NoError(t, os.WriteFile(second, buf.Bytes(), 0o600))
+
+		diffs, err := fileutil.CompareZips(first, second)
+		require.NoError(t, err)
+
+		var got []fileutil.ZipDiff
+		for _, d := range diffs {
+			if d.Kind != fileutil.ZipDiffMode && d.Kind != fileutil.ZipDiffCompressedSize {
+				got = append(got, d)
+			}
+		}
+
+		assert.Equal(t, []fileutil.ZipDiff{
+			{Name: "b.js", Kind: fileutil.ZipDiffMissing},
+			{Name: "d.js", Kind: fileutil.ZipDiffOrder, First: "1", Second: "2"},
+			{Name: "a.js", Kind: fileutil.ZipDiffExtra},
+		}, got)
+	})
+}
+
+func TestOpenZip_crx(t *testing.T) {
+	zipData := &bytes.Buffer{}
+	require.NoError(t, fileutil.WriteZip(zipData, []fileutil.ZipEntry{{Name: "manifest.json", Data: []byte("{}")}}))
+
+	testCases := []struct {
+		name   string
+		header []uint32
+		extra  string
+	}{{
+		name:   "crx2",
+		header: []uint32{2, 3, 4},
+		extra:  "keysign",
+	}, {
+		name:   "crx3",
+		header: []uint32{3, 6},
+		extra:  "header",
+	}, {
+		name:   "zip",
+		header: nil,
+		extra:  "",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if tc.header != nil {
+				buf.WriteString("Cr24")
+				require.NoError(t, binary.Write(buf, binary.LittleEndian, tc.header))
+			}
+
+			buf.WriteString(tc.extra)
+			buf.Write(zipData.Bytes())
+
+			p := filepath.Join(t.TempDir(), "extension.crx")
+			require.NoError(t, os.WriteFile(p, buf.Bytes(), 0o600))
+
+			a, err := fileutil.OpenZip(p)
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, a.Close()) })
+
+			require.Len(t, a.File, 1)
+			assert.Equal(t, "manifest.json", a.File[0].Name)
+		})
+	}
+}
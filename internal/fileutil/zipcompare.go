@@ -0,0 +1,315 @@
+package fileutil
+
+import (
+	"archive/zip"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// ZipDiffKind is the kind of difference between the entries of two archives.
+type ZipDiffKind string
+
+// Kinds of differences reported by CompareZips.
+const (
+	// ZipDiffMissing means the entry is only in the first archive.
+	ZipDiffMissing ZipDiffKind = "missing"
+	// ZipDiffExtra means the entry is only in the second archive.
+	ZipDiffExtra ZipDiffKind = "extra"
+	// ZipDiffContent means the uncompressed contents differ.
+	ZipDiffContent ZipDiffKind = "content"
+	// ZipDiffOrder means the entry is moved relative to the other entries
+	// both archives have.  First and Second are its positions among them.
+	ZipDiffOrder ZipDiffKind = "order"
+	// ZipDiffModified means the modification times differ.
+	ZipDiffModified ZipDiffKind = "modified"
+	// ZipDiffMode means the permissions differ.
+	ZipDiffMode ZipDiffKind = "mode"
+	// ZipDiffMethod means the compression methods differ.
+	ZipDiffMethod ZipDiffKind = "method"
+	// ZipDiffCompressedSize means the same contents are compressed
+	// differently, e.g. with another compression level.
+	ZipDiffCompressedSize ZipDiffKind = "compressed-size"
+	// ZipDiffArchive means all entries match, but the archives still differ,
+	// e.g. in the comments or the extra fields.
+	ZipDiffArchive ZipDiffKind = "archive"
+)
+
+// ZipDiff is a difference between two archives.
+type ZipDiff struct {
+	// Name is the name of the entry, it's empty for ZipDiffArchive.
+	Name string      `json:"name,omitempty"`
+	Kind ZipDiffKind `json:"kind"`
+	// First and Second are the values in the first and the second archive.
+	First  string `json:"first,omitempty"`
+	Second string `json:"second,omitempty"`
+}
+
+// CompareZips compares two zip archives, which may also be CRX files, entry by
+// entry and returns the differences, sorted by the order of the entries in the
+// first archive with the extra entries of the second one at the end.  diffs is
+// empty if the archives are identical byte by byte.
+func CompareZips(first, second string) (diffs []ZipDiff, err error) {
+	firstReader, err := OpenZip(first)
+	if err != nil {
+		return nil, fmt.Errorf("opening %q: %w", first, err)
+	}
+	defer func() { err = errors.WithDeferred(err, firstReader.Close()) }()
+
+	secondReader, err := OpenZip(second)
+	if err != nil {
+		return nil, fmt.Errorf("opening %q: %w", second, err)
+	}
+	defer func() { err = errors.WithDeferred(err, secondReader.Close()) }()
+
+	secondIndex := make(map[string]int, len(secondReader.File))
+	for i, f := range secondReader.File {
+		secondIndex[f.Name] = i
+	}
+
+	firstPos, secondPos, moved := compareZipOrder(firstReader.File, secondReader.File)
+
+	seen := make(map[string]bool, len(firstReader.File))
+	for _, f := range firstReader.File {
+		seen[f.Name] = true
+
+		j, ok := secondIndex[f.Name]
+		if !ok {
+			diffs = append(diffs, ZipDiff{Name: f.Name, Kind: ZipDiffMissing})
+
+			continue
+		}
+
+		var entryDiffs []ZipDiff
+		entryDiffs, err = compareZipEntries(f, secondReader.File[j])
+		if err != nil {
+			return nil, fmt.Errorf("comparing %q: %w", f.Name, err)
+		}
+
+		if moved[f.Name] {
+			entryDiffs = append(entryDiffs, ZipDiff{
+				Kind:   ZipDiffOrder,
+				First:  strconv.Itoa(firstPos[f.Name]),
+				Second: strconv.Itoa(secondPos[f.Name]),
+			})
+		}
+
+		for _, d := range entryDiffs {
+			d.Name = f.Name
+			diffs = append(diffs, d)
+		}
+	}
+
+	for _, f := range secondReader.File {
+		if !seen[f.Name] {
+			diffs = append(diffs, ZipDiff{Name: f.Name, Kind: ZipDiffExtra})
+		}
+	}
+
+	if len(diffs) > 0 {
+		return diffs, nil
+	}
+
+	return compareZipFiles(first, second)
+}
+
+// compareZipOrder compares the relative order of the entries both archives
+// have, so that an added or a removed entry doesn't move the following ones.
+// firstPos and secondPos are the positions of the common entries among them.
+// moved contains the names of the fewest entries, which have to be moved to
+// get the order of the second archive, i.e. the ones outside of the longest
+// common subsequence.
+func compareZipOrder(
+	first []*zip.File,
+	second []*zip.File,
+) (firstPos, secondPos map[string]int, moved map[string]bool) {
+	firstNames := make(map[string]bool, len(first))
+	for _, f := range first {
+		firstNames[f.Name] = true
+	}
+
+	secondPos = map[string]int{}
+	for _, f := range second {
+		if _, ok := secondPos[f.Name]; !ok && firstNames[f.Name] {
+			secondPos[f.Name] = len(secondPos)
+		}
+	}
+
+	// seq is the positions in the second archive of the common entries in
+	// the order of the first one.
+	firstPos = map[string]int{}
+	var names []string
+	var seq []int
+	for _, f := range first {
+		j, ok := secondPos[f.Name]
+		if _, dup := firstPos[f.Name]; !ok || dup {
+			continue
+		}
+
+		firstPos[f.Name] = len(names)
+		names = append(names, f.Name)
+		seq = append(seq, j)
+	}
+
+	moved = map[string]bool{}
+	for i := range seq {
+		moved[names[i]] = true
+	}
+
+	for _, i := range longestIncreasing(seq) {
+		delete(moved, names[i])
+	}
+
+	return firstPos, secondPos, moved
+}
+
+// longestIncreasing returns the indexes of the longest increasing subsequence
+// of seq.
+func longestIncreasing(seq []int) (indexes []int) {
+	// tails[k] is the index of the smallest tail of the increasing
+	// subsequences of length k+1, prev links the elements of them.
+	var tails []int
+	prev := make([]int, len(seq))
+	for i, v := range seq {
+		k, _ := slices.BinarySearchFunc(tails, v, func(t, v int) int { return cmp.Compare(seq[t], v) })
+		if k > 0 {
+			prev[i] = tails[k-1]
+		} else {
+			prev[i] = -1
+		}
+
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	if len(tails) == 0 {
+		return nil
+	}
+
+	indexes = make([]int, len(tails))
+	for k, i := len(tails)-1, tails[len(tails)-1]; k >= 0; k, i = k-1, prev[i] {
+		indexes[k] = i
+	}
+
+	return indexes
+}
+
+// compareZipEntries returns the differences between the entries with the same
+// name, the names of the returned differences aren't set.
+func compareZipEntries(a, b *zip.File) (diffs []ZipDiff, err error) {
+	aHash, err := zipEntryHash(a)
+	if err != nil {
+		return nil, err
+	}
+
+	bHash, err := zipEntryHash(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if aHash != bHash {
+		diffs = append(diffs, ZipDiff{
+			Kind:   ZipDiffContent,
+			First:  fmt.Sprintf("%d bytes, sha256:%s", a.UncompressedSize64, aHash),
+			Second: fmt.Sprintf("%d bytes, sha256:%s", b.UncompressedSize64, bHash),
+		})
+	} else if a.Method == b.Method && a.CompressedSize64 != b.CompressedSize64 {
+		diffs = append(diffs, ZipDiff{
+			Kind:   ZipDiffCompressedSize,
+			First:  strconv.FormatUint(a.CompressedSize64, 10),
+			Second: strconv.FormatUint(b.CompressedSize64, 10),
+		})
+	}
+
+	if !a.Modified.Equal(b.Modified) {
+		diffs = append(diffs, ZipDiff{
+			Kind:   ZipDiffModified,
+			First:  a.Modified.UTC().Format(time.RFC3339),
+			Second: b.Modified.UTC().Format(time.RFC3339),
+		})
+	}
+
+	if a.Mode() != b.Mode() {
+		diffs = append(diffs, ZipDiff{Kind: ZipDiffMode, First: a.Mode().String(), Second: b.Mode().String()})
+	}
+
+	if a.Method != b.Method {
+		diffs = append(diffs, ZipDiff{
+			Kind:   ZipDiffMethod,
+			First:  strconv.Itoa(int(a.Method)),
+			Second: strconv.Itoa(int(b.Method)),
+		})
+	}
+
+	return diffs, nil
+}
+
+// zipEntryHash returns the hex-encoded SHA-256 of the uncompressed contents
+// of the entry.
+func zipEntryHash(f *zip.File) (hash string, err error) {
+	r, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("opening entry: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, r.Close()) }()
+
+	return hashReader(r)
+}
+
+// hashReader returns the hex-encoded SHA-256 of the data read from r.
+func hashReader(r io.Reader) (hash string, err error) {
+	h := sha256.New()
+	_, err = io.Copy(h, r)
+	if err != nil {
+		return "", fmt.Errorf("reading: %w", err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// compareZipFiles returns a ZipDiffArchive difference if the files aren't
+// identical byte by byte.
+func compareZipFiles(first, second string) (diffs []ZipDiff, err error) {
+	firstHash, err := hashFile(first)
+	if err != nil {
+		return nil, err
+	}
+
+	secondHash, err := hashFile(second)
+	if err != nil {
+		return nil, err
+	}
+
+	if firstHash == secondHash {
+		return nil, nil
+	}
+
+	return []ZipDiff{{
+		Kind:   ZipDiffArchive,
+		First:  "sha256:" + firstHash,
+		Second: "sha256:" + secondHash,
+	}}, nil
+}
+
+// hashFile returns the hex-encoded SHA-256 of the file.
+func hashFile(path string) (hash string, err error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("opening %q: %w", path, err)
+	}
+	defer func() { err = errors.WithDeferred(err, f.Close()) }()
+
+	return hashReader(f)
+}
@@ -22,11 +22,11 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/AdguardTeam/golibs/errors"
@@ -34,11 +34,11 @@ import (
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/adguardteam/go-webext/internal/fileutil"
 	"github.com/adguardteam/go-webext/internal/firefox"
-	"github.com/golang-jwt/jwt/v4"
 )
 
-// TODO (maximtop): consider to make this constant an option.
-const requestTimeout = 20 * time.Minute
+// DefaultRequestTimeout is the default timeout of a single request to the
+// store api, including reading the response body.
+const DefaultRequestTimeout = 20 * time.Minute
 
 // TODO make configurable
 // maxReadLimit limits response size returned from the store api.
@@ -55,6 +55,9 @@ const DefaultSourceFilename = "source.zip"
 // AddonsBasePathV5 is a base path for addons api v5.
 const AddonsBasePathV5 = "api/v5/addons"
 
+// ProfilePathV5 is a path of the profile of the authenticated account in api v5.
+const ProfilePathV5 = "api/v5/accounts/profile"
+
 // API represents an instance of a remote API that the client can interact with.
 type API struct {
 	ClientID     string       // ClientID is the ID used for authentication.
@@ -62,6 +65,16 @@ type API struct {
 	now          func() int64 // Now is a function that returns the current Unix time in seconds.
 	URL          *url.URL     // URL is the base URL for the remote API.
 	logger       *slog.Logger // Logger is the logger for the API.
+
+	// requestTimeout is the timeout of a single request to the store api.
+	requestTimeout time.Duration
+
+	// tokenExpiration is the lifetime of the JWT in the auth header.
+	tokenExpiration time.Duration
+
+	// clockSkew is the difference between the server and the local clocks in
+	// seconds, which is added to the time in the JWT claims.
+	clockSkew atomic.Int64
 }
 
 // JoinPath joins the provided path parts with the base URL of the API.
@@ -77,6 +90,14 @@ type Config struct {
 	Now          func() int64 // Now is a function that returns the current Unix time in seconds.
 	URL          *url.URL     // URL is the base URL for the remote API.
 	Logger       *slog.Logger // Logger is the logger for the API.
+
+	// RequestTimeout is the timeout of a single request to the store api.  If
+	// zero, DefaultRequestTimeout is used.
+	RequestTimeout time.Duration
+
+	// TokenExpiration is the lifetime of the JWT in the auth header.  If zero,
+	// MaxTokenExpiration is used, larger values are reduced to it.
+	TokenExpiration time.Duration
 }
 
 // VersionCreateRequest describes version json structure for request to the store api.
@@ -85,6 +106,12 @@ type VersionCreateRequest struct {
 	ApprovalNotes string `json:"approval_notes,omitempty"`
 }
 
+// VersionEditRequest describes version json structure for the version edit
+// request to the store api.
+type VersionEditRequest struct {
+	IsDisabled bool `json:"is_disabled"`
+}
+
 // AddonCreateRequest describes addon json structure to the store api.
 type AddonCreateRequest struct {
 	Version VersionCreateRequest `json:"version"`
@@ -92,7 +119,8 @@ type AddonCreateRequest struct {
 
 // NewAPI creates a new instance of the API with the provided configuration
 // options.  If the Now function is not provided, it defaults to
-// time.Now().Unix().
+// time.Now().Unix().  If the RequestTimeout is not provided, it defaults to
+// DefaultRequestTimeout.  TokenExpiration is limited to MaxTokenExpiration.
 func NewAPI(config Config) *API {
 	c := config
 
@@ -102,32 +130,23 @@ func NewAPI(config Config) *API {
 		}
 	}
 
-	return &API{
-		ClientID:     c.ClientID,
-		ClientSecret: c.ClientSecret,
-		now:          c.Now,
-		URL:          c.URL,
-		logger:       c.Logger,
+	if c.RequestTimeout <= 0 {
+		c.RequestTimeout = DefaultRequestTimeout
 	}
-}
-
-// AuthHeader generates an authorization header that can be used in API
-// requests.  The header contains a JWT token signed with the client's secret.
-func AuthHeader(clientID, clientSecret string, currentTimeSec int64) (result string, err error) {
-	const expirationSec = 5 * 60
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"iss": clientID,
-		"iat": currentTimeSec,
-		"exp": currentTimeSec + expirationSec,
-	})
-
-	signedToken, err := token.SignedString([]byte(clientSecret))
-	if err != nil {
-		return "", fmt.Errorf("signing token: %w", err)
+	if c.TokenExpiration <= 0 || c.TokenExpiration > MaxTokenExpiration {
+		c.TokenExpiration = MaxTokenExpiration
 	}
 
-	return "JWT " + signedToken, nil
+	return &API{
+		ClientID:        c.ClientID,
+		ClientSecret:    c.ClientSecret,
+		now:             c.Now,
+		URL:             c.URL,
+		logger:          c.Logger,
+		requestTimeout:  c.RequestTimeout,
+		tokenExpiration: c.TokenExpiration,
+	}
 }
 
 // prepareRequest creates a new HTTP request object.  The function adds an
@@ -138,12 +157,25 @@ func (a *API) prepareRequest(method, url string, body io.Reader) (*http.Request,
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
-	authHeader, err := AuthHeader(a.ClientID, a.ClientSecret, a.now())
+	err = a.setAuthHeader(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// prepareMultipartRequest creates a new HTTP request object with the
+// streamed multipart body.  The body is closed if the request can't be
+// created.
+func (a *API) prepareMultipartRequest(method, url string, body *multipartBody) (req *http.Request, err error) {
+	req, err = a.prepareRequest(method, url, body.reader)
 	if err != nil {
-		return nil, fmt.Errorf("generating auth header: %w", err)
+		return nil, errors.WithDeferred(err, body.reader.Close())
 	}
 
-	req.Header.Add(httphdr.Authorization, authHeader)
+	req.ContentLength = body.contentLength
+	req.Header.Set(httphdr.ContentType, body.contentType)
 
 	return req, nil
 }
@@ -189,9 +221,7 @@ func (a *API) Status(appID string) (response *firefox.StatusResponse, err error)
 		return nil, fmt.Errorf("preparing request: %w", err)
 	}
 
-	client := &http.Client{Timeout: requestTimeout}
-
-	res, err := client.Do(req)
+	res, err := a.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("sending request: %w", err)
 	}
@@ -238,38 +268,23 @@ func (a *API) CreateUpload(fileData io.Reader, channel firefox.Channel) (result
 	// trailing slash is required
 	apiURL := a.JoinPath("upload", "/")
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	err = writer.WriteField("channel", string(channel))
-	if err != nil {
-		return nil, fmt.Errorf("writing field: %w", err)
-	}
-
-	part, err := writer.CreateFormFile("upload", DefaultExtensionFilename)
-	if err != nil {
-		return nil, fmt.Errorf("creating form file: %w", err)
-	}
-
-	_, err = io.Copy(part, fileData)
-	if err != nil {
-		return nil, fmt.Errorf("copying file error: %w", err)
-	}
-
-	err = writer.Close()
+	body, err := newMultipartBody(
+		map[string]string{"channel": string(channel)},
+		"upload",
+		DefaultExtensionFilename,
+		fileData,
+		l,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("closing writer: %w", err)
+		return nil, fmt.Errorf("creating request body: %w", err)
 	}
 
-	req, err := a.prepareRequest(http.MethodPost, apiURL, body)
+	req, err := a.prepareMultipartRequest(http.MethodPost, apiURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("preparing request: %w", err)
 	}
 
-	req.Header.Set(httphdr.ContentType, writer.FormDataContentType())
-	client := &http.Client{Timeout: requestTimeout}
-
-	res, err := client.Do(req)
+	res, err := a.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("sending request: %w", err)
 	}
@@ -306,9 +321,7 @@ func (a *API) UploadDetail(uuid string) (response *firefox.UploadDetail, err err
 		return nil, fmt.Errorf("preparing request: %w", err)
 	}
 
-	client := &http.Client{Timeout: requestTimeout}
-
-	res, err := client.Do(req)
+	res, err := a.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("sending request: %w", err)
 	}
@@ -352,9 +365,7 @@ func (a *API) CreateAddon(UUID string) (addonInfo *firefox.AddonInfo, err error)
 
 	req.Header.Set(httphdr.ContentType, "application/json")
 
-	client := &http.Client{Timeout: requestTimeout}
-
-	res, err := client.Do(req)
+	res, err := a.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("sending request: %w", err)
 	}
@@ -404,8 +415,7 @@ func (a *API) CreateVersion(appID, UUID, approvalNotes string) (versionInfo *fir
 
 	req.Header.Set(httphdr.ContentType, "application/json")
 
-	client := &http.Client{Timeout: requestTimeout}
-	res, err := client.Do(req)
+	res, err := a.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("sending request: %w", err)
 	}
@@ -452,9 +462,7 @@ func (a *API) VersionsList(appID string) ([]*firefox.VersionInfo, error) {
 		q.Add("page", strconv.Itoa(page))
 		req.URL.RawQuery = q.Encode()
 
-		client := &http.Client{Timeout: requestTimeout}
-
-		res, err := client.Do(req)
+		res, err := a.do(req)
 		if err != nil {
 			return nil, fmt.Errorf("sending request: %w", err)
 		}
@@ -497,9 +505,7 @@ func (a *API) VersionDetail(appID, versionID string) (versionInfo *firefox.Versi
 	}
 
 	req.Header.Set(httphdr.ContentType, "application/json")
-	client := &http.Client{Timeout: requestTimeout}
-
-	res, err := client.Do(req)
+	res, err := a.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("sending request: %w", err)
 	}
@@ -526,41 +532,139 @@ func (a *API) VersionDetail(appID, versionID string) (versionInfo *firefox.Versi
 	return versionInfo, nil
 }
 
-// AttachSourceToVersion uploads source code to the specified version.
-// https://addons-server.readthedocs.io/en/latest/topics/api/addons.html#version-sources
-func (a *API) AttachSourceToVersion(appID, versionID string, sourceData io.Reader) (err error) {
-	l := a.logger.With(slogutil.KeyPrefix, "AttachSourceToVersion", "appID", appID, "versionID", versionID)
-	l.Debug("attaching source to version")
+// Profile returns the profile of the account the credentials belong to.  It's
+// the cheapest authenticated request, so it's used to verify credentials.
+// https://addons-server.readthedocs.io/en/latest/topics/api/accounts.html#profile
+func (a *API) Profile() (profile *firefox.Profile, err error) {
+	l := a.logger.With(slogutil.KeyPrefix, "Profile")
+	l.Debug("retrieving profile")
+
+	// trailing slash is required
+	apiURL := a.URL.JoinPath(ProfilePathV5, "/").String()
+
+	req, err := a.prepareRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("preparing request: %w", err)
+	}
+
+	res, err := a.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, res.Body.Close()) }()
+
+	body, err := readBody(res, []int{http.StatusOK})
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+
+	err = json.Unmarshal(body, &profile)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling response body: %s, error: %w", body, err)
+	}
+
+	l.Debug("profile retrieved", "id", profile.ID)
+
+	return profile, nil
+}
+
+// SetVersionDisabled disables or re-enables the specified version.
+// https://addons-server.readthedocs.io/en/latest/topics/api/addons.html#version-edit
+func (a *API) SetVersionDisabled(appID, versionID string, disabled bool) (versionInfo *firefox.VersionInfo, err error) {
+	l := a.logger.With(
+		slogutil.KeyPrefix, "SetVersionDisabled",
+		"appID", appID,
+		"versionID", versionID,
+		"disabled", disabled,
+	)
+	l.Debug("editing version")
 
 	apiURL := a.JoinPath("addon", appID, "versions", versionID, "/")
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+	jsonBody, err := json.Marshal(VersionEditRequest{IsDisabled: disabled})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling request body: %w", err)
+	}
 
-	part, err := writer.CreateFormFile("source", DefaultSourceFilename)
+	req, err := a.prepareRequest(http.MethodPatch, apiURL, bytes.NewReader(jsonBody))
 	if err != nil {
-		return fmt.Errorf("creating form file: %w", err)
+		return nil, fmt.Errorf("preparing request: %w", err)
 	}
 
-	_, err = io.Copy(part, sourceData)
+	req.Header.Set(httphdr.ContentType, "application/json")
+	res, err := a.do(req)
 	if err != nil {
-		return fmt.Errorf("copying file error: %w", err)
+		return nil, fmt.Errorf("sending request: %w", err)
 	}
+	defer func() { err = errors.WithDeferred(err, res.Body.Close()) }()
 
-	err = writer.Close()
+	body, err := readBody(res, []int{http.StatusOK})
 	if err != nil {
-		return fmt.Errorf("closing writer: %w", err)
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+
+	err = json.Unmarshal(body, &versionInfo)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling response body: %s, error: %w", body, err)
 	}
 
-	req, err := a.prepareRequest(http.MethodPatch, apiURL, body)
+	l.Debug(
+		"version edit completed",
+		"version", versionInfo,
+		"status", "success",
+	)
+
+	return versionInfo, nil
+}
+
+// DeleteVersion deletes the specified version.
+// https://addons-server.readthedocs.io/en/latest/topics/api/addons.html#version-delete
+func (a *API) DeleteVersion(appID, versionID string) (err error) {
+	l := a.logger.With(slogutil.KeyPrefix, "DeleteVersion", "appID", appID, "versionID", versionID)
+	l.Debug("deleting version")
+
+	apiURL := a.JoinPath("addon", appID, "versions", versionID, "/")
+
+	req, err := a.prepareRequest(http.MethodDelete, apiURL, nil)
 	if err != nil {
 		return fmt.Errorf("preparing request: %w", err)
 	}
 
-	req.Header.Set(httphdr.ContentType, writer.FormDataContentType())
-	client := &http.Client{Timeout: requestTimeout}
+	res, err := a.do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, res.Body.Close()) }()
 
-	res, err := client.Do(req)
+	_, err = readBody(res, []int{http.StatusNoContent})
+	if err != nil {
+		return fmt.Errorf("reading response body: %w", err)
+	}
+
+	l.Debug("version deleted", "status", "success")
+
+	return nil
+}
+
+// AttachSourceToVersion uploads source code to the specified version.
+// https://addons-server.readthedocs.io/en/latest/topics/api/addons.html#version-sources
+func (a *API) AttachSourceToVersion(appID, versionID string, sourceData io.Reader) (err error) {
+	l := a.logger.With(slogutil.KeyPrefix, "AttachSourceToVersion", "appID", appID, "versionID", versionID)
+	l.Debug("attaching source to version")
+
+	apiURL := a.JoinPath("addon", appID, "versions", versionID, "/")
+
+	body, err := newMultipartBody(nil, "source", DefaultSourceFilename, sourceData, l)
+	if err != nil {
+		return fmt.Errorf("creating request body: %w", err)
+	}
+
+	req, err := a.prepareMultipartRequest(http.MethodPatch, apiURL, body)
+	if err != nil {
+		return fmt.Errorf("preparing request: %w", err)
+	}
+
+	res, err := a.do(req)
 	if err != nil {
 		return fmt.Errorf("sending request: %w", err)
 	}
@@ -579,33 +683,40 @@ func (a *API) AttachSourceToVersion(appID, versionID string, sourceData io.Reade
 	return nil
 }
 
-// DownloadSignedByURL downloads extension by url.
-func (a *API) DownloadSignedByURL(url string) (response []byte, err error) {
+// DownloadSignedByURL downloads extension by url and streams it to w.  The
+// download progress is logged.
+func (a *API) DownloadSignedByURL(url string, w io.Writer) (err error) {
 	l := a.logger.With(slogutil.KeyPrefix, "DownloadSignedByURL", "url", url)
 	l.Debug("downloading signed extension")
 
-	client := http.Client{Timeout: requestTimeout}
-
 	req, err := a.prepareRequest(http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("preparing request: %w", err)
+		return fmt.Errorf("preparing request: %w", err)
 	}
 
-	res, err := client.Do(req)
+	res, err := a.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("sending request: %w", err)
+		return fmt.Errorf("sending request: %w", err)
 	}
 	defer func() { err = errors.WithDeferred(err, res.Body.Close()) }()
 
-	responseBody, err := readBody(res, []int{http.StatusOK})
+	if res.StatusCode != http.StatusOK {
+		_, err = readBody(res, []int{http.StatusOK})
+
+		return fmt.Errorf("reading response body: %w", err)
+	}
+
+	pr := fileutil.NewProgressReader(res.Body, res.ContentLength, l)
+
+	_, err = io.Copy(w, pr)
 	if err != nil {
-		return nil, fmt.Errorf("reading response body: %w", err)
+		return fmt.Errorf("reading response body: %w", err)
 	}
 
 	l.Debug(
 		"signed extension downloaded",
-		"body", responseBody,
+		"bytes", pr.BytesRead(),
 	)
 
-	return responseBody, nil
+	return nil
 }
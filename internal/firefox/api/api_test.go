@@ -1,6 +1,7 @@
 package api_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -16,6 +17,7 @@ import (
 	"github.com/adguardteam/go-webext/internal/firefox"
 	"github.com/adguardteam/go-webext/internal/firefox/api"
 	"github.com/adguardteam/go-webext/internal/urlutil"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -34,6 +36,31 @@ const (
 	testUUID     = "test_uuid"
 )
 
+// assertAuthHeader asserts that r has the authorization header with the JWT
+// signed with the test credentials.
+func assertAuthHeader(t require.TestingT, r *http.Request) {
+	tokenString, ok := strings.CutPrefix(r.Header.Get(httphdr.Authorization), "JWT ")
+	require.True(t, ok)
+
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation(), jwt.WithValidMethods([]string{"HS256"}))
+	_, err := parser.ParseWithClaims(tokenString, claims, func(_ *jwt.Token) (any, error) {
+		return []byte(clientSecret), nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, clientID, claims["iss"])
+	assert.NotEmpty(t, claims["jti"])
+
+	iat, ok := claims["iat"].(float64)
+	require.True(t, ok)
+
+	exp, ok := claims["exp"].(float64)
+	require.True(t, ok)
+
+	assert.Equal(t, api.MaxTokenExpiration.Seconds(), exp-iat)
+}
+
 func TestStatus(t *testing.T) {
 	expectedStatus := &firefox.StatusResponse{
 		ID:             appID,
@@ -49,10 +76,7 @@ func TestStatus(t *testing.T) {
 		assert.Equal(t, r.URL.Path, urlutil.JoinPath(api.AddonsBasePathV5, "addon", appID))
 
 		// assert that has auth header
-		authHeader, err := api.AuthHeader(clientID, clientSecret, testTime)
-		require.NoError(pt, err)
-
-		assert.Equal(t, r.Header.Get(httphdr.Authorization), authHeader)
+		assertAuthHeader(pt, r)
 
 		w.WriteHeader(http.StatusOK)
 
@@ -100,14 +124,11 @@ func TestDownloadSignedByURL(t *testing.T) {
 		assert.Equal(t, r.URL.Path, expectedURLPath)
 
 		// assert that has auth header
-		authHeader, err := api.AuthHeader(clientID, clientSecret, testTime)
-		require.NoError(pt, err)
-
-		assert.Equal(t, r.Header.Get(httphdr.Authorization), authHeader)
+		assertAuthHeader(pt, r)
 
 		w.WriteHeader(http.StatusOK)
 
-		_, err = w.Write(expectedResponse)
+		_, err := w.Write(expectedResponse)
 		require.NoError(pt, err)
 	}))
 	defer storeServer.Close()
@@ -123,10 +144,11 @@ func TestDownloadSignedByURL(t *testing.T) {
 		Logger:       slogutil.NewDiscardLogger(),
 	})
 
-	response, err := firefoxAPI.DownloadSignedByURL(storeURL.JoinPath(expectedURLPath).String())
+	response := &bytes.Buffer{}
+	err = firefoxAPI.DownloadSignedByURL(storeURL.JoinPath(expectedURLPath).String(), response)
 	require.NoError(t, err)
 
-	assert.Equal(t, expectedResponse, response)
+	assert.Equal(t, expectedResponse, response.Bytes())
 }
 
 func TestCreateUpload(t *testing.T) {
@@ -145,10 +167,7 @@ func TestCreateUpload(t *testing.T) {
 		pt := testutil.PanicT{}
 		assert.Equal(t, http.MethodPost, r.Method)
 		assert.Equal(t, r.URL.Path, urlutil.JoinPath(api.AddonsBasePathV5, "upload", "/"))
-		authHeader, err := api.AuthHeader(clientID, clientSecret, testTime)
-		require.NoError(pt, err)
-
-		assert.Equal(t, r.Header.Get(httphdr.Authorization), authHeader)
+		assertAuthHeader(pt, r)
 
 		// assert that has field channel in request
 		assert.Equal(t, r.FormValue("channel"), "listed")
@@ -213,9 +232,7 @@ func TestUploadDetail(t *testing.T) {
 		assert.Equal(t, http.MethodGet, r.Method)
 		assert.Equal(t, r.URL.Path, urlutil.JoinPath(api.AddonsBasePathV5, "upload", expectedUUID))
 
-		authHeader, err := api.AuthHeader(clientID, clientSecret, testTime)
-		require.NoError(pt, err)
-		assert.Equal(t, r.Header.Get(httphdr.Authorization), authHeader)
+		assertAuthHeader(pt, r)
 
 		expectedResponse, err := json.Marshal(expectedUploadDetail)
 		require.NoError(pt, err)
@@ -256,9 +273,7 @@ func TestCreateAddon(t *testing.T) {
 		assert.Equal(t, http.MethodPost, r.Method)
 		assert.Equal(t, r.URL.Path, urlutil.JoinPath(api.AddonsBasePathV5, "addon", "/"))
 
-		authHeader, err := api.AuthHeader(clientID, clientSecret, testTime)
-		require.NoError(pt, err)
-		assert.Equal(t, r.Header.Get(httphdr.Authorization), authHeader)
+		assertAuthHeader(pt, r)
 
 		// read body
 		body, err := io.ReadAll(r.Body)
@@ -304,9 +319,7 @@ func TestAttachSourceToVersion(t *testing.T) {
 		assert.Equal(t, http.MethodPatch, r.Method)
 		assert.Equal(t, r.URL.Path, urlutil.JoinPath(api.AddonsBasePathV5, "addon", appID, "versions", testUUID, "/"))
 
-		authHeader, err := api.AuthHeader(clientID, clientSecret, testTime)
-		require.NoError(pt, err)
-		assert.Equal(t, r.Header.Get(httphdr.Authorization), authHeader)
+		assertAuthHeader(pt, r)
 
 		// assert that has file in request body
 		file, header, err := r.FormFile("source")
@@ -351,9 +364,7 @@ func TestCreateVersion(t *testing.T) {
 		assert.Equal(t, http.MethodPost, r.Method)
 		assert.Equal(t, r.URL.Path, urlutil.JoinPath(api.AddonsBasePathV5, "addon", appID, "versions", "/"))
 
-		authHeader, err := api.AuthHeader(clientID, clientSecret, testTime)
-		require.NoError(pt, err)
-		assert.Equal(t, r.Header.Get(httphdr.Authorization), authHeader)
+		assertAuthHeader(pt, r)
 
 		body, err := io.ReadAll(r.Body)
 		require.NoError(pt, err)
@@ -454,9 +465,7 @@ func TestVersionDetail(t *testing.T) {
 		assert.Equal(t, http.MethodGet, r.Method)
 		assert.Equal(t, r.URL.Path, urlutil.JoinPath(api.AddonsBasePathV5, "addon", appID, "versions", versionID, "/"))
 
-		authHeader, err := api.AuthHeader(clientID, clientSecret, testTime)
-		require.NoError(pt, err)
-		assert.Equal(t, r.Header.Get(httphdr.Authorization), authHeader)
+		assertAuthHeader(pt, r)
 
 		response, err := json.Marshal(expectedVersionInfo)
 		require.NoError(pt, err)
@@ -497,9 +506,7 @@ func TestVersionsList(t *testing.T) {
 		assert.Equal(t, http.MethodGet, r.Method)
 		assert.Equal(t, r.URL.Path, urlutil.JoinPath(api.AddonsBasePathV5, "addon", appID, "versions", "/"))
 
-		authHeader, err := api.AuthHeader(clientID, clientSecret, testTime)
-		require.NoError(pt, err)
-		assert.Equal(t, r.Header.Get(httphdr.Authorization), authHeader)
+		assertAuthHeader(pt, r)
 
 		response, err := json.Marshal(firefox.VersionsListResponse{
 			Count: 1,
@@ -534,3 +541,81 @@ func TestVersionsList(t *testing.T) {
 
 	assert.Equal(t, []*firefox.VersionInfo{expectedVersionInfo}, versionsList)
 }
+
+func TestSetVersionDisabled(t *testing.T) {
+	expectedVersionInfo := &firefox.VersionInfo{
+		ID:         12345,
+		IsDisabled: true,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pt := testutil.PanicT{}
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, r.URL.Path, urlutil.JoinPath(api.AddonsBasePathV5, "addon", appID, "versions", versionID, "/"))
+
+		assertAuthHeader(pt, r)
+
+		var actualRequest api.VersionEditRequest
+		err := json.NewDecoder(r.Body).Decode(&actualRequest)
+		require.NoError(pt, err)
+
+		assert.True(t, actualRequest.IsDisabled)
+
+		response, err := json.Marshal(expectedVersionInfo)
+		require.NoError(pt, err)
+
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write(response)
+		require.NoError(pt, err)
+	}))
+
+	defer server.Close()
+
+	storeURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	firefoxAPI := api.NewAPI(api.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Now: func() int64 {
+			return testTime
+		},
+		URL:    storeURL,
+		Logger: slogutil.NewDiscardLogger(),
+	})
+
+	versionInfo, err := firefoxAPI.SetVersionDisabled(appID, versionID, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, expectedVersionInfo, versionInfo)
+}
+
+func TestDeleteVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pt := testutil.PanicT{}
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, r.URL.Path, urlutil.JoinPath(api.AddonsBasePathV5, "addon", appID, "versions", versionID, "/"))
+
+		assertAuthHeader(pt, r)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	storeURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	firefoxAPI := api.NewAPI(api.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Now: func() int64 {
+			return testTime
+		},
+		URL:    storeURL,
+		Logger: slogutil.NewDiscardLogger(),
+	})
+
+	err = firefoxAPI.DeleteVersion(appID, versionID)
+	require.NoError(t, err)
+}
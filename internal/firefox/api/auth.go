@@ -0,0 +1,179 @@
+package api //nolint:revive // "api" is a clear and conventional name for an API client sub-package
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// MaxTokenExpiration is the maximum lifetime of the JWT accepted by AMO.
+// https://mozilla.github.io/addons-server/topics/api/auth.html
+const MaxTokenExpiration = 5 * time.Minute
+
+// clockSkewThreshold is the minimum difference between the server and the
+// local clocks that is compensated.  Smaller differences are caused by the
+// one-second precision of the Date header and the network latency.
+const clockSkewThreshold = 10 * time.Second
+
+// jtiSize is the size of the random JWT ID in bytes.
+const jtiSize = 16
+
+// AuthHeader generates an authorization header that can be used in API
+// requests.  The header contains a JWT token signed with the client's secret,
+// issued at currentTimeSec, expiring after expiration and having a random JWT
+// ID, so that AMO never sees the same token twice.
+func AuthHeader(clientID, clientSecret string, currentTimeSec int64, expiration time.Duration) (result string, err error) {
+	jti, err := newJTI()
+	if err != nil {
+		return "", fmt.Errorf("generating jwt id: %w", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss": clientID,
+		"jti": jti,
+		"iat": currentTimeSec,
+		"exp": currentTimeSec + int64(expiration.Seconds()),
+	})
+
+	signedToken, err := token.SignedString([]byte(clientSecret))
+	if err != nil {
+		return "", fmt.Errorf("signing token: %w", err)
+	}
+
+	return "JWT " + signedToken, nil
+}
+
+// newJTI returns a random hex-encoded JWT ID.
+func newJTI() (jti string, err error) {
+	b := make([]byte, jtiSize)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// credentialsIssuerRe matches the JWT issuer of AMO API keys, e.g.
+// "user:12345:67".
+var credentialsIssuerRe = regexp.MustCompile(`^user:\d+:\d+$`)
+
+// credentialsSecretRe matches the JWT secret of AMO API keys.
+var credentialsSecretRe = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// CheckCredentials returns an error if clientID or clientSecret don't look
+// like AMO API credentials, e.g. when they are swapped or truncated.
+func CheckCredentials(clientID, clientSecret string) (err error) {
+	var errs []error
+	if !credentialsIssuerRe.MatchString(clientID) {
+		errs = append(errs, errors.Error(`client id doesn't look like a JWT issuer, e.g. "user:12345:67"`))
+	}
+
+	if !credentialsSecretRe.MatchString(clientSecret) {
+		errs = append(errs, errors.Error("client secret doesn't look like a JWT secret of 64 hex characters"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// setAuthHeader sets the authorization header of req using the local time
+// adjusted for the known clock skew.
+func (a *API) setAuthHeader(req *http.Request) (err error) {
+	authHeader, err := AuthHeader(a.ClientID, a.ClientSecret, a.now()+a.clockSkew.Load(), a.tokenExpiration)
+	if err != nil {
+		return fmt.Errorf("generating auth header: %w", err)
+	}
+
+	req.Header.Set(httphdr.Authorization, authHeader)
+
+	return nil
+}
+
+// do sends the request and records the clock skew from the response.  If the
+// request is rejected as unauthorized because of the newly detected clock
+// skew, it's signed again and retried once, provided its body can be re-read.
+func (a *API) do(req *http.Request) (res *http.Response, err error) {
+	client := &http.Client{Timeout: a.requestTimeout}
+
+	res, err = client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	skewChanged := a.recordClockSkew(res)
+	if res.StatusCode != http.StatusUnauthorized || !skewChanged {
+		return res, nil
+	}
+
+	if req.Body != nil && req.GetBody == nil {
+		a.logger.Warn("request rejected due to clock skew, rerun the command to retry")
+
+		return res, nil
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return res, nil
+		}
+	}
+
+	err = a.setAuthHeader(retry)
+	if err != nil {
+		return nil, errors.WithDeferred(err, res.Body.Close())
+	}
+
+	err = res.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("closing unauthorized response body: %w", err)
+	}
+
+	a.logger.Info("retrying request with adjusted clock", "url", req.URL.Redacted())
+
+	return client.Do(retry)
+}
+
+// recordClockSkew compares the Date header of res with the local clock and
+// stores the difference if it's over clockSkewThreshold.  It returns true if
+// the stored skew has changed.
+func (a *API) recordClockSkew(res *http.Response) (changed bool) {
+	date := res.Header.Get(httphdr.Date)
+	if date == "" {
+		return false
+	}
+
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		a.logger.Debug("parsing date header", "date", date, slogutil.KeyError, err)
+
+		return false
+	}
+
+	skew := serverTime.Unix() - a.now()
+	if time.Duration(max(skew, -skew))*time.Second < clockSkewThreshold {
+		skew = 0
+	}
+
+	prev := a.clockSkew.Swap(skew)
+	if prev == skew {
+		return false
+	}
+
+	if skew != 0 {
+		a.logger.Warn(
+			"local clock differs from the server clock, adjusting jwt time",
+			"skew", time.Duration(skew)*time.Second,
+		)
+	}
+
+	return true
+}
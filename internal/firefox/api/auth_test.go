@@ -0,0 +1,109 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/adguardteam/go-webext/internal/firefox"
+	"github.com/adguardteam/go-webext/internal/firefox/api"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthHeader(t *testing.T) {
+	first, err := api.AuthHeader(clientID, clientSecret, testTime, time.Minute)
+	require.NoError(t, err)
+
+	second, err := api.AuthHeader(clientID, clientSecret, testTime, time.Minute)
+	require.NoError(t, err)
+
+	// Tokens have random IDs, so they differ even for the same time.
+	assert.NotEqual(t, first, second)
+
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	_, err = parser.ParseWithClaims(strings.TrimPrefix(first, "JWT "), claims, func(_ *jwt.Token) (any, error) {
+		return []byte(clientSecret), nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(testTime), claims["iat"])
+	assert.Equal(t, float64(testTime+60), claims["exp"])
+}
+
+func TestCheckCredentials(t *testing.T) {
+	validSecret := strings.Repeat("0123456789abcdef", 4)
+
+	assert.NoError(t, api.CheckCredentials("user:12345:67", validSecret))
+	assert.Error(t, api.CheckCredentials(validSecret, "user:12345:67"))
+	assert.Error(t, api.CheckCredentials("user:12345:67", validSecret[:10]))
+}
+
+func TestProfile_clockSkew(t *testing.T) {
+	expectedProfile := &firefox.Profile{
+		ID:       12345,
+		Username: "test_user",
+	}
+
+	requests := 0
+	storeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pt := testutil.PanicT{}
+		requests++
+
+		assert.Equal(pt, "/"+api.ProfilePathV5+"/", r.URL.Path)
+		assertAuthHeader(pt, r)
+
+		claims := jwt.MapClaims{}
+		parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+		_, _, err := parser.ParseUnverified(
+			strings.TrimPrefix(r.Header.Get(httphdr.Authorization), "JWT "),
+			claims,
+		)
+		require.NoError(pt, err)
+
+		// Reject tokens issued too far from the server time, as AMO does.
+		iat, _ := claims["iat"].(float64)
+		if time.Since(time.Unix(int64(iat), 0)).Abs() > time.Minute {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		err = json.NewEncoder(w).Encode(expectedProfile)
+		require.NoError(pt, err)
+	}))
+	defer storeServer.Close()
+
+	storeURL, err := url.Parse(storeServer.URL)
+	require.NoError(t, err)
+
+	firefoxAPI := api.NewAPI(api.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		// The local clock is far behind the server one.
+		Now:    func() int64 { return testTime },
+		URL:    storeURL,
+		Logger: slogutil.NewDiscardLogger(),
+	})
+
+	profile, err := firefoxAPI.Profile()
+	require.NoError(t, err)
+
+	assert.Equal(t, expectedProfile, profile)
+	assert.Equal(t, 2, requests)
+
+	// The skew is remembered, so the next request succeeds at once.
+	_, err = firefoxAPI.Profile()
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, requests)
+}
@@ -0,0 +1,147 @@
+package api //nolint:revive // "api" is a clear and conventional name for an API client sub-package
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"mime/multipart"
+	"os"
+	"slices"
+
+	"github.com/adguardteam/go-webext/internal/fileutil"
+)
+
+// multipartBody is a multipart/form-data request body streamed from the file
+// instead of being buffered in memory.
+type multipartBody struct {
+	// reader is the read end of the pipe the form is written to.  It must be
+	// closed if the request isn't sent.
+	reader io.ReadCloser
+	// contentType is the value of the Content-Type header with the boundary.
+	contentType string
+	// contentLength is the size of the whole body, it's -1 if the size of the
+	// file is unknown.
+	contentLength int64
+}
+
+// newMultipartBody returns a multipart/form-data body with fields and a file
+// part named fileField which contents are read from fileData.  The form is
+// written to a pipe by a separate goroutine and the upload progress is logged
+// with l.
+func newMultipartBody(
+	fields map[string]string,
+	fileField string,
+	fileName string,
+	fileData io.Reader,
+	l *slog.Logger,
+) (body *multipartBody, err error) {
+	// Compute the size of everything but the file contents using a writer
+	// with the same boundary.
+	counter := &countingWriter{}
+	counterWriter := multipart.NewWriter(counter)
+	err = writeMultipart(counterWriter, fields, fileField, fileName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("computing form size: %w", err)
+	}
+
+	contentLength := int64(-1)
+	fileSize := readerSize(fileData)
+	if fileSize >= 0 {
+		contentLength = counter.n + fileSize
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	err = mw.SetBoundary(counterWriter.Boundary())
+	if err != nil {
+		return nil, fmt.Errorf("setting boundary: %w", err)
+	}
+
+	progress := fileutil.NewProgressReader(fileData, fileSize, l)
+
+	go func() {
+		pw.CloseWithError(writeMultipart(mw, fields, fileField, fileName, progress))
+	}()
+
+	return &multipartBody{
+		reader:        pr,
+		contentType:   mw.FormDataContentType(),
+		contentLength: contentLength,
+	}, nil
+}
+
+// writeMultipart writes the form to mw and closes it.  If fileData is nil, the
+// file part is written without contents.  Fields are written in the sorted
+// order, so that the size of the form is the same for the same input.
+func writeMultipart(
+	mw *multipart.Writer,
+	fields map[string]string,
+	fileField string,
+	fileName string,
+	fileData io.Reader,
+) (err error) {
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		err = mw.WriteField(k, fields[k])
+		if err != nil {
+			return fmt.Errorf("writing field: %w", err)
+		}
+	}
+
+	part, err := mw.CreateFormFile(fileField, fileName)
+	if err != nil {
+		return fmt.Errorf("creating form file: %w", err)
+	}
+
+	if fileData != nil {
+		_, err = io.Copy(part, fileData)
+		if err != nil {
+			return fmt.Errorf("copying file error: %w", err)
+		}
+	}
+
+	err = mw.Close()
+	if err != nil {
+		return fmt.Errorf("closing writer: %w", err)
+	}
+
+	return nil
+}
+
+// readerSize returns the number of bytes left in r or -1 if it can't be
+// determined.
+func readerSize(r io.Reader) (size int64) {
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case *os.File:
+		fi, err := r.Stat()
+		if err != nil || !fi.Mode().IsRegular() {
+			return -1
+		}
+
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+
+		return fi.Size() - offset
+	default:
+		return -1
+	}
+}
+
+// countingWriter is an io.Writer that counts the bytes written to it.
+type countingWriter struct {
+	n int64
+}
+
+// type check
+var _ io.Writer = (*countingWriter)(nil)
+
+// Write implements the io.Writer interface for *countingWriter.
+func (w *countingWriter) Write(p []byte) (n int, err error) {
+	w.n += int64(len(p))
+
+	return len(p), nil
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMultipartBody(t *testing.T) {
+	const content = "test content"
+
+	path := filepath.Join(t.TempDir(), "extension.zip")
+	err := os.WriteFile(path, []byte(content), 0o600)
+	require.NoError(t, err)
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, file.Close()) })
+
+	testCases := []struct {
+		fileData   io.Reader
+		name       string
+		wantLength bool
+	}{{
+		fileData:   strings.NewReader(content),
+		name:       "reader_with_len",
+		wantLength: true,
+	}, {
+		fileData:   file,
+		name:       "file",
+		wantLength: true,
+	}, {
+		fileData:   io.MultiReader(strings.NewReader(content)),
+		name:       "unknown_size",
+		wantLength: false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := newMultipartBody(
+				map[string]string{"channel": "listed"},
+				"upload",
+				DefaultExtensionFilename,
+				tc.fileData,
+				slogutil.NewDiscardLogger(),
+			)
+			require.NoError(t, err)
+
+			data, err := io.ReadAll(body.reader)
+			require.NoError(t, err)
+
+			if tc.wantLength {
+				assert.Equal(t, int64(len(data)), body.contentLength)
+			} else {
+				assert.Equal(t, int64(-1), body.contentLength)
+			}
+
+			_, params, err := mime.ParseMediaType(body.contentType)
+			require.NoError(t, err)
+
+			form, err := multipart.NewReader(strings.NewReader(string(data)), params["boundary"]).ReadForm(1024)
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"listed"}, form.Value["channel"])
+			require.Len(t, form.File["upload"], 1)
+
+			f, err := form.File["upload"][0].Open()
+			require.NoError(t, err)
+
+			uploaded, err := io.ReadAll(f)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(uploaded))
+		})
+	}
+}
@@ -3,51 +3,55 @@ package firefox
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/adguardteam/go-webext/internal/fileutil"
+	"github.com/adguardteam/go-webext/internal/manifest"
 )
 
 // Store type describes store structure.
 type Store struct {
-	api    API
-	logger *slog.Logger
+	api            API
+	logger         *slog.Logger
+	failOnWarnings bool
+	pollConfig     PollConfig
 }
 
 // StoreConfig contains configuration parameters for creating a Firefox extension store instance
 type StoreConfig struct {
 	API    API
 	Logger *slog.Logger
+	// FailOnWarnings makes uploads with validation warnings fail the same way
+	// as uploads with validation errors.
+	FailOnWarnings bool
+	// Poll contains the intervals and timeouts of waiting for validation and
+	// signing, zero values are replaced with defaults.
+	Poll PollConfig
 }
 
 // NewStore creates a new Firefox extension store instance
 func NewStore(config StoreConfig) *Store {
 	return &Store{
-		api:    config.API,
-		logger: config.Logger,
+		api:            config.API,
+		logger:         config.Logger,
+		failOnWarnings: config.FailOnWarnings,
+		pollConfig:     config.Poll.withDefaults(),
 	}
 }
 
-type gecko struct {
-	ID string `json:"id"`
-}
-
-type applications struct {
-	Gecko gecko `json:"gecko"`
-}
-
-type browserSpecificSettings struct {
-	Gecko gecko `json:"gecko"`
-}
-
 // Channel represents channel type of extension either listed or unlisted.
 type Channel string
 
@@ -70,59 +74,34 @@ func NewChannel(channel string) (Channel, error) {
 	}
 }
 
-// manifest describes required fields parsed from the manifest.
-// extensions might have either "applications" or "browser_specific_settings"
-type manifest struct {
-	Version                 string                  `json:"Version"`
-	Applications            applications            `json:"applications"`
-	BrowserSpecificSettings browserSpecificSettings `json:"browser_specific_settings"`
-}
-
-// parseManifest reads zip archive, and extracts manifest.json out of it.
-func parseManifest(zipFilepath string) (result manifest, err error) {
-	fileContent, err := fileutil.ReadFileFromZip(zipFilepath, "manifest.json")
-	if err != nil {
-		return manifest{}, fmt.Errorf("can't read manifest.json from zip file %q due to: %w", zipFilepath, err)
-	}
-
-	err = json.Unmarshal(fileContent, &result)
-	if err != nil {
-		return manifest{}, fmt.Errorf("can't unmarshal manifest.json %q due to: %w", zipFilepath, err)
-	}
-
-	return result, nil
-}
-
 // extensionData various form of different extension data extracted from manifest.
 type extensionData struct {
-	appID   string
-	version string
+	appID            string
+	version          string
+	strictMinVersion string
 }
 
 // extDataFromFile retrieves extensionData from manifest and validates it.
 func extDataFromFile(zipFilepath string) (*extensionData, error) {
-	manifest, err := parseManifest(zipFilepath)
+	m, err := manifest.ReadZip(zipFilepath)
 	if err != nil {
 		return nil, fmt.Errorf("can't parse manifest: %w", err)
 	}
 
-	resultData := &extensionData{}
-
-	if manifest.Applications.Gecko.ID != "" {
-		resultData.appID = manifest.Applications.Gecko.ID
-	} else if manifest.BrowserSpecificSettings.Gecko.ID != "" {
-		resultData.appID = manifest.BrowserSpecificSettings.Gecko.ID
-	} else {
+	gecko := m.Gecko()
+	if gecko == nil {
 		return nil, fmt.Errorf("can't get appID from manifest: %q", zipFilepath)
 	}
 
-	if manifest.Version == "" {
+	if m.Version == "" {
 		return nil, fmt.Errorf("can't get Version from manifest: %q", zipFilepath)
 	}
 
-	resultData.version = manifest.Version
-
-	return resultData, nil
+	return &extensionData{
+		appID:            gecko.ID,
+		version:          m.Version,
+		strictMinVersion: gecko.StrictMinVersion,
+	}, nil
 }
 
 // StatusResponse represents a generic response from the status request.
@@ -132,9 +111,33 @@ type StatusResponse struct {
 	CurrentVersion string
 }
 
+// Profile describes the AMO account the API credentials belong to.
+type Profile struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	URL      string `json:"url"`
+}
+
+// File statuses reported by AMO.
+const (
+	// FileStatusPublic is a status of the approved and signed file.
+	FileStatusPublic = "public"
+	// FileStatusDisabled is a status of the rejected or disabled file.
+	FileStatusDisabled = "disabled"
+	// FileStatusNominated is a status of the file awaiting review.
+	FileStatusNominated = "nominated"
+)
+
 // FileInfo represents file info structure.
 type FileInfo struct {
-	ID     int    `json:"id"`
+	ID      int       `json:"id"`
+	Created time.Time `json:"created"`
+	// Hash is the hash of the file in the "algorithm:hex" form, e.g.
+	// "sha256:...".
+	Hash   string `json:"hash"`
+	Size   int64  `json:"size"`
 	Status string `json:"status"`
 	URL    string `json:"url"`
 }
@@ -249,13 +252,13 @@ type UploadDetail struct {
 	Submitted  bool        `json:"submitted"`
 	URL        string      `json:"url"`
 	Valid      bool        `json:"valid"`
-	Validation interface{} `json:"validation"`
+	Validation *Validation `json:"validation"`
 	Version    string      `json:"Version"`
 }
 
 // API is an interface for the store client.
 type API interface {
-	DownloadSignedByURL(url string) ([]byte, error)
+	DownloadSignedByURL(url string, w io.Writer) error
 	Status(appID string) (*StatusResponse, error)
 	CreateUpload(fileData io.Reader, c Channel) (*UploadDetail, error)
 	UploadDetail(UUID string) (*UploadDetail, error)
@@ -264,62 +267,65 @@ type API interface {
 	CreateAddon(UUID string) (*AddonInfo, error)
 	AttachSourceToVersion(appID, versionID string, sourceData io.Reader) (err error)
 	VersionsList(appID string) ([]*VersionInfo, error)
+	SetVersionDisabled(appID, versionID string, disabled bool) (*VersionInfo, error)
+	DeleteVersion(appID, versionID string) error
+	Profile() (*Profile, error)
 }
 
-// awaitUploadValidation awaits validation of the upload.
-func (s *Store) awaitUploadValidation(UUID string) (err error) {
+// awaitUploadValidation awaits validation of the upload and returns the
+// processed upload details.
+func (s *Store) awaitUploadValidation(UUID string) (uploadDetail *UploadDetail, err error) {
 	l := s.logger.With("action", "awaitUploadValidation", "uuid", UUID)
 	l.Debug("awaiting upload validation")
 
-	// TODO (maximtop): move constants to config
-	// with one 1 second timeout request may be throttled, so we use 5 seconds
-	const retryInterval = time.Second * 5
-	const maxAwaitTime = time.Minute * 20
+	err = s.poll(l, s.pollConfig.ValidationTimeout, func() (done bool, err error) {
+		uploadDetail, err = s.api.UploadDetail(UUID)
+		if err != nil {
+			return false, fmt.Errorf("getting upload status: %w", err)
+		}
 
-	startTime := time.Now()
+		return uploadDetail.Processed, nil
+	})
+	if err != nil {
+		// Don't wrap the error, the callers do.
+		return nil, err
+	}
 
-	for {
-		if elapsed := time.Since(startTime); elapsed > maxAwaitTime {
-			return fmt.Errorf("await validation timeout after %v, maximum allowed time is %v", elapsed, maxAwaitTime)
-		}
+	err = checkValidation(uploadDetail, s.failOnWarnings)
+	if err != nil {
+		l.Debug("extension validation failed", "url", uploadDetail.URL)
 
-		uploadDetail, err := s.api.UploadDetail(UUID)
-		if err != nil {
-			return fmt.Errorf("getting upload status: %w", err)
-		}
+		return nil, err
+	}
+
+	l.Debug("extension validation successful")
+	s.logValidationMessages(uploadDetail.Validation)
 
-		if uploadDetail.Processed {
-			if uploadDetail.Valid {
-				l.Debug("extension validation successful")
-			} else {
-				l.Debug("extension validation failed")
-
-				// Pretty print upload details
-				prettyJSON, err := json.MarshalIndent(uploadDetail, "", "    ")
-				if err != nil {
-					return fmt.Errorf("failed to generate pretty JSON: %w", err)
-				}
-
-				// Log pretty printed JSON
-				l.Debug(
-					"upload validation details",
-					"stage", "awaitUploadValidation",
-					"raw_details", prettyJSON,
-				)
-
-				return fmt.Errorf("not valid, validation url: %s", uploadDetail.URL)
-			}
-			break
+	return uploadDetail, nil
+}
+
+// logValidationMessages logs warnings and notices of the successful
+// validation, so that they are visible without opening AMO.
+func (s *Store) logValidationMessages(v *Validation) {
+	if v == nil {
+		return
+	}
+
+	for _, m := range v.Messages {
+		lvl := slog.LevelInfo
+		if m.Type != MessageTypeNotice {
+			lvl = slog.LevelWarn
 		}
-		l.Debug(
-			"upload processing pending",
-			"retry_interval", retryInterval,
-			"status", "pending",
+
+		s.logger.Log(
+			context.Background(),
+			lvl,
+			"validation "+string(m.Type),
+			"code", m.Code(),
+			"location", m.Location(),
+			"message", m.Message,
 		)
-		time.Sleep(retryInterval)
 	}
-
-	return nil
 }
 
 // awaitSigning waits for the extension to be signed.
@@ -327,43 +333,34 @@ func (s *Store) awaitVersionSigning(appID, versionID string) (err error) {
 	l := s.logger.With("action", "awaitVersionSigning", "appID", appID, "versionID", versionID)
 	l.Debug("start waiting for signing of extension")
 
-	// TODO (maximtop): move constants to config
-	// with one 1 second timeout request may be throttled, so we use 5 seconds
-	const retryInterval = time.Second * 5
-	const maxAwaitTime = time.Minute * 20
-
-	startTime := time.Now()
-
-	for {
-		if time.Since(startTime) > maxAwaitTime {
-			return fmt.Errorf("await signing timeout")
-		}
-
+	err = s.poll(l, s.pollConfig.SigningTimeout, func() (done bool, err error) {
 		versionDetail, err := s.api.VersionDetail(appID, versionID)
 		if err != nil {
-			return fmt.Errorf("getting upload status for appID: %s, versionID: %s, due to: %w", appID, versionID, err)
+			return false, fmt.Errorf("getting upload status for appID: %s, versionID: %s, due to: %w", appID, versionID, err)
 		}
 
-		if versionDetail.File.Status == "public" {
-			l.Debug("extension is signed and ready")
-			return nil
-		}
-		if versionDetail.File.Status == "disabled" {
-			return fmt.Errorf("extension won't be signed automatically, version detail: %+v", versionDetail)
+		switch versionDetail.File.Status {
+		case FileStatusPublic:
+			return true, nil
+		case FileStatusDisabled:
+			return false, fmt.Errorf("extension won't be signed automatically, version detail: %+v", versionDetail)
+		default:
+			return false, nil
 		}
+	})
+	if err != nil {
+		return fmt.Errorf("awaiting signing: %w", err)
+	}
 
-		l.Debug(
-			"extension signing pending",
-			"retry_interval", retryInterval,
-			"status", "pending",
-		)
+	l.Debug("extension is signed and ready")
 
-		time.Sleep(retryInterval)
-	}
+	return nil
 }
 
 // downloadSigned downloads signed extension.
-// If output is empty, then it will be set to "firefox.xpi".
+// If output is empty, then it will be set to "firefox.xpi".  The file is
+// streamed to a temporary file, verified against the hash reported by AMO and
+// then renamed to output, so output never contains a partial download.
 func (s *Store) downloadSigned(appID, versionID, output string) error {
 	l := s.logger.With("action", "downloadSigned", "appID", appID)
 	l.Debug("initiating signed extension download")
@@ -379,27 +376,76 @@ func (s *Store) downloadSigned(appID, versionID, output string) error {
 
 	downloadURL := versionDetail.File.URL
 
-	response, err := s.api.DownloadSignedByURL(downloadURL)
+	h, expectedSum, err := newFileHash(versionDetail.File.Hash)
 	if err != nil {
-		return fmt.Errorf("downloading signed extension: %s, due to: %w", downloadURL, err)
+		return fmt.Errorf("parsing file hash: %w", err)
 	}
 
-	file, err := os.Create(filepath.Clean(output))
-	if err != nil {
-		return fmt.Errorf("creating file: %s due to: %w", output, err)
-	}
+	err = fileutil.WriteFileAtomic(output, 0o644, func(f *os.File) (err error) {
+		err = s.api.DownloadSignedByURL(downloadURL, io.MultiWriter(f, h))
+		if err != nil {
+			return fmt.Errorf("downloading signed extension: %s, due to: %w", downloadURL, err)
+		}
+
+		if expectedSum == nil {
+			l.Debug("file hash is not reported, skipping verification")
 
-	_, err = io.Copy(file, bytes.NewReader(response))
+			return nil
+		}
+
+		if actualSum := h.Sum(nil); !bytes.Equal(actualSum, expectedSum) {
+			return fmt.Errorf(
+				"checksum mismatch: expected %x, got %x",
+				expectedSum,
+				actualSum,
+			)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("writing response to file: %s due to: %w", output, err)
+		return fmt.Errorf("saving file: %s due to: %w", output, err)
 	}
-	defer func() { err = errors.WithDeferred(err, file.Close()) }()
 
 	l.Debug("successfully downloaded signed extension")
 
 	return nil
 }
 
+// newFileHash returns the hash function and the expected checksum for the
+// fileHash reported by AMO in the "algorithm:hex" form.  If fileHash is empty,
+// the returned checksum is nil.
+func newFileHash(fileHash string) (h hash.Hash, sum []byte, err error) {
+	if fileHash == "" {
+		return sha256.New(), nil, nil
+	}
+
+	algo, hexSum, ok := strings.Cut(fileHash, ":")
+	if !ok {
+		return nil, nil, fmt.Errorf("bad hash format: %q", fileHash)
+	}
+
+	switch algo {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return nil, nil, fmt.Errorf("unsupported hash algorithm: %q", algo)
+	}
+
+	sum, err = hex.DecodeString(hexSum)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decoding hash %q: %w", fileHash, err)
+	}
+
+	if len(sum) != h.Size() {
+		return nil, nil, fmt.Errorf("bad %s hash length: %d", algo, len(sum))
+	}
+
+	return h, sum, nil
+}
+
 // Status returns status of the extension by appID.
 func (s *Store) Status(appID string) (result *StatusResponse, err error) {
 	l := s.logger.With("action", "Status", "appID", appID)
@@ -419,11 +465,32 @@ func (s *Store) Status(appID string) (result *StatusResponse, err error) {
 	return response, nil
 }
 
+// WhoAmI returns the profile of the account the API credentials belong to,
+// verifying that AMO accepts them.
+func (s *Store) WhoAmI() (profile *Profile, err error) {
+	l := s.logger.With("action", "WhoAmI")
+	l.Debug("verifying credentials")
+
+	profile, err = s.api.Profile()
+	if err != nil {
+		return nil, fmt.Errorf("getting profile: %w", err)
+	}
+
+	l.Debug("credentials verified", "id", profile.ID, "username", profile.Username)
+
+	return profile, nil
+}
+
 // Insert uploads extension to the amo for the first time.
 func (s *Store) Insert(filePath, sourcepath string) (err error) {
 	l := s.logger.With("action", "Insert", "filePath", filePath, "sourcePath", sourcepath)
 	l.Debug("initiating new extension upload")
 
+	err = checkSourceSize(sourcepath)
+	if err != nil {
+		return err
+	}
+
 	file, err := os.Open(filepath.Clean(filePath))
 	if err != nil {
 		return fmt.Errorf("opening file: %q, due to: %w", filePath, err)
@@ -442,7 +509,7 @@ func (s *Store) Insert(filePath, sourcepath string) (err error) {
 		"upload", uploadDetail,
 	)
 
-	err = s.awaitUploadValidation(uploadDetail.UUID)
+	_, err = s.awaitUploadValidation(uploadDetail.UUID)
 	if err != nil {
 		return fmt.Errorf("awaiting validation: %w", err)
 	}
@@ -454,18 +521,12 @@ func (s *Store) Insert(filePath, sourcepath string) (err error) {
 
 	// We can't append the source before the addon is created.
 	if sourcepath != "" {
-		sourceReader, err := os.Open(filepath.Clean(sourcepath))
-		if err != nil {
-			return fmt.Errorf("opening file: %q, due to: %w", sourcepath, err)
-		}
-		defer func() { err = errors.WithDeferred(err, sourceReader.Close()) }()
-
 		extData, err := extDataFromFile(filePath)
 		if err != nil {
 			return fmt.Errorf("parsing manifest: %q, error: %w", filePath, err)
 		}
 
-		err = s.api.AttachSourceToVersion(extData.appID, strconv.Itoa(addonInfo.Version.ID), sourceReader)
+		err = s.attachSource(extData.appID, strconv.Itoa(addonInfo.Version.ID), sourcepath)
 		if err != nil {
 			return fmt.Errorf("uploading source: %w", err)
 		}
@@ -474,12 +535,60 @@ func (s *Store) Insert(filePath, sourcepath string) (err error) {
 	return nil
 }
 
+// attachSource uploads the source archive at sourcepath to the version.
+func (s *Store) attachSource(appID, versionID, sourcepath string) (err error) {
+	sourceReader, err := os.Open(filepath.Clean(sourcepath))
+	if err != nil {
+		return fmt.Errorf("opening file: %q, due to: %w", sourcepath, err)
+	}
+	defer func() { err = errors.WithDeferred(err, sourceReader.Close()) }()
+
+	return s.api.AttachSourceToVersion(appID, versionID, sourceReader)
+}
+
+// Validate uploads the extension to the store and waits for the validation
+// results without creating a new version.  If the upload doesn't pass the
+// validation, the returned error is a *ValidationError.
+func (s *Store) Validate(extpath string, channel Channel) (result *Validation, err error) {
+	l := s.logger.With("action", "Validate", "extpath", extpath, "channel", channel)
+	l.Debug("initiating extension validation")
+
+	file, err := os.Open(filepath.Clean(extpath))
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %q, due to: %w", extpath, err)
+	}
+	defer func() { err = errors.WithDeferred(err, file.Close()) }()
+
+	uploadDetail, err := s.api.CreateUpload(file, channel)
+	if err != nil {
+		return nil, fmt.Errorf("creating upload: %w", err)
+	}
+
+	uploadDetail, err = s.awaitUploadValidation(uploadDetail.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("awaiting validation: %w", err)
+	}
+
+	l.Debug("extension validation completed", "url", uploadDetail.URL)
+
+	if uploadDetail.Validation == nil {
+		return &Validation{Success: true}, nil
+	}
+
+	return uploadDetail.Validation, nil
+}
+
 // Update uploads new Version of extension to the store
 // Before uploading it reads manifest.json for getting extension Version and uuid.
 func (s *Store) Update(extpath, sourcepath string, channel Channel, approvalNotes string) (err error) {
 	l := s.logger.With("action", "Update", "extpath", extpath, "sourcepath", sourcepath, "channel", channel)
 	l.Debug("initiating extension update")
 
+	err = checkSourceSize(sourcepath)
+	if err != nil {
+		return err
+	}
+
 	cleanExtPath := filepath.Clean(extpath)
 	extData, err := extDataFromFile(cleanExtPath)
 	if err != nil {
@@ -499,7 +608,7 @@ func (s *Store) Update(extpath, sourcepath string, channel Channel, approvalNote
 		return fmt.Errorf("creating upload: %w", err)
 	}
 
-	err = s.awaitUploadValidation(uploadDetail.UUID)
+	_, err = s.awaitUploadValidation(uploadDetail.UUID)
 	if err != nil {
 		return fmt.Errorf("awaiting validation: %w", err)
 	}
@@ -510,12 +619,7 @@ func (s *Store) Update(extpath, sourcepath string, channel Channel, approvalNote
 	}
 
 	if sourcepath != "" {
-		cleanSourcePath := filepath.Clean(sourcepath)
-		sourceReader, err := os.Open(cleanSourcePath)
-		if err != nil {
-			return fmt.Errorf("opening file: %q, due to: %w", cleanSourcePath, err)
-		}
-		err = s.api.AttachSourceToVersion(appID, strconv.Itoa(versionInfo.ID), sourceReader)
+		err = s.attachSource(appID, strconv.Itoa(versionInfo.ID), sourcepath)
 		if err != nil {
 			return fmt.Errorf("attaching source to version: %w", err)
 		}
@@ -587,27 +691,46 @@ func (s *Store) isSigned(appID, versionID string) (bool, error) {
 	}
 
 	switch versionDetail.File.Status {
-	case "public":
+	case FileStatusPublic:
 		l.Debug(
 			"full version details",
 			"version", versionDetail,
 		)
 
 		return true, nil
-	case "disabled":
+	case FileStatusDisabled:
 		return false, fmt.Errorf("extension will not be signed automatically, version detail: %+v", versionDetail)
 	default:
-		return false, fmt.Errorf("extension is pending signature, version detail: %+v", versionDetail)
+		l.Debug("extension is pending signature", "status", versionDetail.File.Status)
+
+		return false, nil
 	}
 }
 
+// ErrSigningPending is returned by Sign with SignOptions.NoWait when the
+// version is submitted but not signed yet.
+const ErrSigningPending errors.Error = "version is pending signature"
+
+// SignOptions represents the options for signing.
+type SignOptions struct {
+	// NoWait makes Sign return ErrSigningPending instead of waiting for the
+	// signing of the submitted or already existing version.
+	NoWait bool
+}
+
 // Sign uploads the extension to the store, waits for the signing process to complete, then downloads and saves the signed
 // extension in the specified directory. The unlisted channel is always used for signing.
-// If the extension is already uploaded, it will be downloaded and saved in the specified directory.
-func (s *Store) Sign(extpath, sourcepath, output, approvalNotes string) (err error) {
+// If the version is already uploaded, Sign waits for it to be signed, unless opts.NoWait is set, and downloads it, so
+// that reruns of the failed signing complete the job.
+func (s *Store) Sign(extpath, sourcepath, output, approvalNotes string, opts SignOptions) (err error) {
 	l := s.logger.With("action", "Sign", "extpath", extpath, "sourcepath", sourcepath)
 	l.Debug("initiating extension signing")
 
+	err = checkSourceSize(sourcepath)
+	if err != nil {
+		return err
+	}
+
 	cleanExtPath := filepath.Clean(extpath)
 	extData, err := extDataFromFile(cleanExtPath)
 	if err != nil {
@@ -617,30 +740,13 @@ func (s *Store) Sign(extpath, sourcepath, output, approvalNotes string) (err err
 	appID := extData.appID
 	version := extData.version
 
-	// if the extension is already uploaded and signed, download it
+	// if the extension is already uploaded, resume waiting for it and download
 	versionID, err := s.hasVersion(appID, version)
 	if err != nil {
 		return fmt.Errorf("checking version: %w", err)
 	}
 	if versionID != "" {
-		isSigned, err := s.isSigned(appID, versionID)
-		if err != nil {
-			return fmt.Errorf("checking if extension is signed: %w", err)
-		}
-		if isSigned {
-			err = s.downloadSigned(appID, versionID, output)
-			if err != nil {
-				return fmt.Errorf("error downloading already existing and signed extension '%s' with versionID '%s': %w", appID, versionID, err)
-			}
-			return nil
-		}
-		l.Info(
-			"extension uploaded but not signed",
-			"app_id", appID,
-			"version", version,
-			"status", "pending_signature",
-		)
-		return nil
+		return s.resumeSigning(appID, version, versionID, output, opts)
 	}
 
 	file, err := os.Open(filepath.Clean(extpath))
@@ -654,7 +760,7 @@ func (s *Store) Sign(extpath, sourcepath, output, approvalNotes string) (err err
 		return fmt.Errorf("error creating upload: %w", err)
 	}
 
-	err = s.awaitUploadValidation(uploadDetail.UUID)
+	_, err = s.awaitUploadValidation(uploadDetail.UUID)
 	if err != nil {
 		return fmt.Errorf("error waiting for validation: %w", err)
 	}
@@ -667,17 +773,53 @@ func (s *Store) Sign(extpath, sourcepath, output, approvalNotes string) (err err
 	versionID = strconv.Itoa(versionInfo.ID)
 
 	if sourcepath != "" {
-		cleanSourcePath := filepath.Clean(sourcepath)
-		sourceReader, err := os.Open(cleanSourcePath)
+		err = s.attachSource(appID, versionID, sourcepath)
 		if err != nil {
-			return fmt.Errorf("opening file: %q, due to: %w", cleanSourcePath, err)
+			return fmt.Errorf("error attaching source to version: %w", err)
 		}
-		err = s.api.AttachSourceToVersion(appID, versionID, sourceReader)
+	}
+
+	if opts.NoWait {
+		l.Info("version submitted, not waiting for signing", "app_id", appID, "version", version)
+
+		return ErrSigningPending
+	}
+
+	return s.awaitAndDownload(appID, versionID, output)
+}
+
+// resumeSigning downloads the already uploaded version of the extension,
+// waiting for it to be signed first if needed.
+func (s *Store) resumeSigning(appID, version, versionID, output string, opts SignOptions) (err error) {
+	l := s.logger.With("action", "resumeSigning", "appID", appID, "version", version, "versionID", versionID)
+
+	isSigned, err := s.isSigned(appID, versionID)
+	if err != nil {
+		return fmt.Errorf("checking if extension is signed: %w", err)
+	}
+
+	if isSigned {
+		err = s.downloadSigned(appID, versionID, output)
 		if err != nil {
-			return fmt.Errorf("error attaching source to version: %w", err)
+			return fmt.Errorf("error downloading already existing and signed extension '%s' with versionID '%s': %w", appID, versionID, err)
 		}
+
+		return nil
 	}
 
+	if opts.NoWait {
+		l.Info("extension uploaded but not signed", "status", "pending_signature")
+
+		return ErrSigningPending
+	}
+
+	l.Info("extension uploaded but not signed, waiting for signing", "status", "pending_signature")
+
+	return s.awaitAndDownload(appID, versionID, output)
+}
+
+// awaitAndDownload waits for the version to be signed and downloads it.
+func (s *Store) awaitAndDownload(appID, versionID, output string) (err error) {
 	err = s.awaitVersionSigning(appID, versionID)
 	if err != nil {
 		return fmt.Errorf("error waiting for signing of extension '%s' with versionID '%s': %w", appID, versionID, err)
@@ -1,7 +1,10 @@
 package firefox
 
 import (
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
@@ -35,12 +38,12 @@ func TestExtDataFromFile(t *testing.T) {
 
 type testAPI struct {
 	API
-	onDownloadSignedByURL func(url string) ([]byte, error)
+	onDownloadSignedByURL func(url string, w io.Writer) error
 	onVersionDetail       func(appID, versionID string) (*VersionInfo, error)
 }
 
-func (a *testAPI) DownloadSignedByURL(url string) ([]byte, error) {
-	return a.onDownloadSignedByURL(url)
+func (a *testAPI) DownloadSignedByURL(url string, w io.Writer) error {
+	return a.onDownloadSignedByURL(url, w)
 }
 
 func (a *testAPI) VersionDetail(appID, version string) (*VersionInfo, error) {
@@ -63,9 +66,10 @@ func TestDownloadSigned(t *testing.T) {
 			require.Equal(t, testVersion, version)
 			return versionInfo, nil
 		},
-		onDownloadSignedByURL: func(url string) ([]byte, error) {
+		onDownloadSignedByURL: func(url string, w io.Writer) error {
 			require.Equal(t, expectedURL, url)
-			return []byte("test"), nil
+			_, err := io.WriteString(w, "test")
+			return err
 		},
 	}
 
@@ -89,3 +93,56 @@ func TestDownloadSigned(t *testing.T) {
 		}
 	})
 }
+
+func TestDownloadSigned_checksum(t *testing.T) {
+	const content = "test"
+
+	// sha256 of "test".
+	const validHash = "sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	newStore := func(hash string) *Store {
+		return NewStore(StoreConfig{
+			API: &testAPI{
+				onVersionDetail: func(_, _ string) (*VersionInfo, error) {
+					return &VersionInfo{File: FileInfo{URL: "https://example.org/f.xpi", Hash: hash}}, nil
+				},
+				onDownloadSignedByURL: func(_ string, w io.Writer) error {
+					_, err := io.WriteString(w, content)
+					return err
+				},
+			},
+			Logger: slogutil.NewDiscardLogger(),
+		})
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "firefox.xpi")
+
+		err := newStore(validHash).downloadSigned(testAppID, testVersion, output)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(output)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		dir := t.TempDir()
+		output := filepath.Join(dir, "firefox.xpi")
+
+		badHash := "sha256:" + strings.Repeat("0", 64)
+		err := newStore(badHash).downloadSigned(testAppID, testVersion, output)
+		require.ErrorContains(t, err, "checksum mismatch")
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "firefox.xpi")
+
+		err := newStore("md5:098f6bcd4621d373cade4e832627b4f6").downloadSigned(testAppID, testVersion, output)
+		require.Error(t, err)
+	})
+}
@@ -3,11 +3,13 @@ package firefox_test
 import (
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/adguardteam/go-webext/internal/firefox"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -31,8 +33,11 @@ type MockAPI struct {
 	onAttachSourceToVersion func(appID, versionID string, sourceData io.Reader) error
 	onCreateVersion         func(appID, UUID, approvalNotes string) (*firefox.VersionInfo, error)
 	onVersionDetail         func(appID, versionID string) (*firefox.VersionInfo, error)
-	onDownloadSignedByURL   func(url string) ([]byte, error)
+	onDownloadSignedByURL   func(url string, w io.Writer) error
 	onVersionsList          func(appID string) ([]*firefox.VersionInfo, error)
+	onSetVersionDisabled    func(appID, versionID string, disabled bool) (*firefox.VersionInfo, error)
+	onDeleteVersion         func(appID, versionID string) error
+	onProfile               func() (*firefox.Profile, error)
 }
 
 func (m *MockAPI) Status(appID string) (*firefox.StatusResponse, error) {
@@ -63,14 +68,26 @@ func (m *MockAPI) VersionDetail(appID, versionID string) (*firefox.VersionInfo,
 	return m.onVersionDetail(appID, versionID)
 }
 
-func (m *MockAPI) DownloadSignedByURL(url string) ([]byte, error) {
-	return m.onDownloadSignedByURL(url)
+func (m *MockAPI) DownloadSignedByURL(url string, w io.Writer) error {
+	return m.onDownloadSignedByURL(url, w)
 }
 
 func (m *MockAPI) VersionsList(appID string) ([]*firefox.VersionInfo, error) {
 	return m.onVersionsList(appID)
 }
 
+func (m *MockAPI) SetVersionDisabled(appID, versionID string, disabled bool) (*firefox.VersionInfo, error) {
+	return m.onSetVersionDisabled(appID, versionID, disabled)
+}
+
+func (m *MockAPI) DeleteVersion(appID, versionID string) error {
+	return m.onDeleteVersion(appID, versionID)
+}
+
+func (m *MockAPI) Profile() (*firefox.Profile, error) {
+	return m.onProfile()
+}
+
 func TestStatus(t *testing.T) {
 	expectedStatus := &firefox.StatusResponse{
 		ID:             testAppID,
@@ -301,10 +318,10 @@ func TestSign(t *testing.T) {
 				},
 			}, nil
 		},
-		onDownloadSignedByURL: func(url string) ([]byte, error) {
+		onDownloadSignedByURL: func(url string, _ io.Writer) error {
 			require.Equal(t, testURL, url)
 
-			return []byte(""), nil
+			return nil
 		},
 		onVersionsList: func(appID string) ([]*firefox.VersionInfo, error) {
 			return []*firefox.VersionInfo{}, nil
@@ -315,7 +332,7 @@ func TestSign(t *testing.T) {
 		Logger: slogutil.NewDiscardLogger(),
 	})
 
-	err := store.Sign(testFilepath, testSourcepath, expectedFilename, "")
+	err := store.Sign(testFilepath, testSourcepath, expectedFilename, "", firefox.SignOptions{})
 	require.NoError(t, err)
 
 	// Check if the sourcefile exists.
@@ -370,8 +387,8 @@ func TestSignWithApprovalNotes(t *testing.T) {
 				},
 			}, nil
 		},
-		onDownloadSignedByURL: func(url string) ([]byte, error) {
-			return []byte(""), nil
+		onDownloadSignedByURL: func(_ string, _ io.Writer) error {
+			return nil
 		},
 		onVersionsList: func(appID string) ([]*firefox.VersionInfo, error) {
 			return []*firefox.VersionInfo{}, nil
@@ -382,7 +399,7 @@ func TestSignWithApprovalNotes(t *testing.T) {
 		Logger: slogutil.NewDiscardLogger(),
 	})
 
-	err := store.Sign(testFilepath, testSourcepath, expectedFilename, expectedNotes)
+	err := store.Sign(testFilepath, testSourcepath, expectedFilename, expectedNotes, firefox.SignOptions{})
 	require.NoError(t, err)
 
 	_, err = os.Stat(expectedFilename)
@@ -395,3 +412,65 @@ func TestSignWithApprovalNotes(t *testing.T) {
 		}
 	})
 }
+
+func TestSign_pendingVersion(t *testing.T) {
+	newStore := func(t *testing.T, detailCalls *int) *firefox.Store {
+		t.Helper()
+
+		mockAPI := &MockAPI{
+			onVersionsList: func(_ string) ([]*firefox.VersionInfo, error) {
+				return []*firefox.VersionInfo{{ID: testVersionID, Version: testVersion}}, nil
+			},
+			onVersionDetail: func(_, versionID string) (*firefox.VersionInfo, error) {
+				require.Equal(t, strconv.Itoa(testVersionID), versionID)
+
+				*detailCalls++
+				status := firefox.FileStatusNominated
+				if *detailCalls > 2 {
+					status = firefox.FileStatusPublic
+				}
+
+				return &firefox.VersionInfo{
+					File: firefox.FileInfo{Status: status, URL: testURL},
+				}, nil
+			},
+			onCreateUpload: func(_ io.Reader, _ firefox.Channel) (*firefox.UploadDetail, error) {
+				panic("existing version must not be uploaded again")
+			},
+			onDownloadSignedByURL: func(url string, _ io.Writer) error {
+				require.Equal(t, testURL, url)
+
+				return nil
+			},
+		}
+
+		return firefox.NewStore(firefox.StoreConfig{
+			API:    mockAPI,
+			Logger: slogutil.NewDiscardLogger(),
+			Poll:   testPollConfig,
+		})
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		detailCalls := 0
+		store := newStore(t, &detailCalls)
+		output := filepath.Join(t.TempDir(), "firefox.xpi")
+
+		err := store.Sign(testFilepath, "", output, "", firefox.SignOptions{})
+		require.NoError(t, err)
+
+		assert.FileExists(t, output)
+	})
+
+	t.Run("no_wait", func(t *testing.T) {
+		detailCalls := 0
+		store := newStore(t, &detailCalls)
+		output := filepath.Join(t.TempDir(), "firefox.xpi")
+
+		err := store.Sign(testFilepath, "", output, "", firefox.SignOptions{NoWait: true})
+		require.ErrorIs(t, err, firefox.ErrSigningPending)
+
+		assert.Equal(t, 1, detailCalls)
+		assert.NoFileExists(t, output)
+	})
+}
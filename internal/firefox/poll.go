@@ -0,0 +1,108 @@
+package firefox
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+const (
+	// DefaultPollInterval is the default initial interval between the status
+	// requests.  With a 1 second interval requests may be throttled, so 5
+	// seconds are used.
+	DefaultPollInterval = 5 * time.Second
+
+	// DefaultMaxPollInterval is the default upper limit of the interval
+	// between the status requests.
+	DefaultMaxPollInterval = 1 * time.Minute
+
+	// DefaultValidationTimeout is the default maximum time to wait for the
+	// upload validation.
+	DefaultValidationTimeout = 20 * time.Minute
+
+	// DefaultSigningTimeout is the default maximum time to wait for the
+	// version signing.
+	DefaultSigningTimeout = 20 * time.Minute
+)
+
+// pollBackoffFactor is the factor the interval between the status requests is
+// multiplied by after each pending response.
+const pollBackoffFactor = 2
+
+// ErrTimeout is returned when the store doesn't finish processing within the
+// configured timeout.
+const ErrTimeout errors.Error = "timed out waiting for the store"
+
+// PollConfig contains the intervals and timeouts used while waiting for AMO to
+// validate uploads and sign versions.  Zero values are replaced with defaults.
+type PollConfig struct {
+	// Interval is the initial interval between the status requests.
+	Interval time.Duration
+	// MaxInterval is the upper limit of the interval, which grows
+	// exponentially while the status is pending.
+	MaxInterval time.Duration
+	// ValidationTimeout is the maximum time to wait for the upload
+	// validation.
+	ValidationTimeout time.Duration
+	// SigningTimeout is the maximum time to wait for the version signing.
+	SigningTimeout time.Duration
+}
+
+// withDefaults returns a copy of c with zero values replaced with defaults.
+func (c PollConfig) withDefaults() (res PollConfig) {
+	res = c
+
+	if res.Interval <= 0 {
+		res.Interval = DefaultPollInterval
+	}
+
+	if res.MaxInterval <= 0 {
+		res.MaxInterval = DefaultMaxPollInterval
+	}
+
+	res.MaxInterval = max(res.MaxInterval, res.Interval)
+
+	if res.ValidationTimeout <= 0 {
+		res.ValidationTimeout = DefaultValidationTimeout
+	}
+
+	if res.SigningTimeout <= 0 {
+		res.SigningTimeout = DefaultSigningTimeout
+	}
+
+	return res
+}
+
+// poll calls check until it reports that it's done or returns an error.  The
+// interval between the calls starts at the configured interval and is
+// multiplied by pollBackoffFactor after each call up to the maximum interval.
+// If check isn't done within timeout, an error wrapping ErrTimeout is
+// returned.
+func (s *Store) poll(l *slog.Logger, timeout time.Duration, check func() (done bool, err error)) (err error) {
+	interval := s.pollConfig.Interval
+	startTime := time.Now()
+
+	for {
+		var done bool
+		done, err = check()
+		if err != nil {
+			return err
+		} else if done {
+			return nil
+		}
+
+		elapsed := time.Since(startTime)
+		if elapsed >= timeout {
+			return fmt.Errorf("%w after %v, maximum allowed time is %v", ErrTimeout, elapsed, timeout)
+		}
+
+		wait := min(interval, timeout-elapsed)
+		l.Debug("processing pending", "retry_interval", wait, "elapsed", elapsed)
+
+		time.Sleep(wait)
+
+		interval = min(interval*pollBackoffFactor, s.pollConfig.MaxInterval)
+	}
+}
@@ -0,0 +1,78 @@
+package firefox_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/adguardteam/go-webext/internal/firefox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPollConfig is the poll configuration with short intervals for tests.
+var testPollConfig = firefox.PollConfig{
+	Interval:          time.Millisecond,
+	MaxInterval:       4 * time.Millisecond,
+	ValidationTimeout: 50 * time.Millisecond,
+	SigningTimeout:    50 * time.Millisecond,
+}
+
+func TestUpdate_pollPending(t *testing.T) {
+	const pendingResponses = 3
+
+	calls := 0
+	mockAPI := &MockAPI{
+		onCreateUpload: func(_ io.Reader, _ firefox.Channel) (*firefox.UploadDetail, error) {
+			return &firefox.UploadDetail{UUID: testUUID}, nil
+		},
+		onUploadDetail: func(_ string) (*firefox.UploadDetail, error) {
+			calls++
+
+			return &firefox.UploadDetail{
+				UUID:      testUUID,
+				Processed: calls > pendingResponses,
+				Valid:     true,
+			}, nil
+		},
+		onCreateVersion: func(_, _, _ string) (*firefox.VersionInfo, error) {
+			return &firefox.VersionInfo{ID: testVersionID}, nil
+		},
+	}
+
+	store := firefox.NewStore(firefox.StoreConfig{
+		API:    mockAPI,
+		Logger: slogutil.NewDiscardLogger(),
+		Poll:   testPollConfig,
+	})
+
+	err := store.Update(testFilepath, "", testChannel, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, pendingResponses+1, calls)
+}
+
+func TestUpdate_pollTimeout(t *testing.T) {
+	mockAPI := &MockAPI{
+		onCreateUpload: func(_ io.Reader, _ firefox.Channel) (*firefox.UploadDetail, error) {
+			return &firefox.UploadDetail{UUID: testUUID}, nil
+		},
+		onUploadDetail: func(_ string) (*firefox.UploadDetail, error) {
+			return &firefox.UploadDetail{UUID: testUUID}, nil
+		},
+		onCreateVersion: func(_, _, _ string) (*firefox.VersionInfo, error) {
+			panic("version must not be created")
+		},
+	}
+
+	store := firefox.NewStore(firefox.StoreConfig{
+		API:    mockAPI,
+		Logger: slogutil.NewDiscardLogger(),
+		Poll:   testPollConfig,
+	})
+
+	err := store.Update(testFilepath, "", testChannel, "")
+	assert.True(t, errors.Is(err, firefox.ErrTimeout))
+}
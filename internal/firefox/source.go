@@ -0,0 +1,238 @@
+package firefox
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/adguardteam/go-webext/internal/fileutil"
+)
+
+// MaxSourceSize is the maximum size of the source code archive accepted by
+// AMO.
+const MaxSourceSize = 200 * fileutil.MB
+
+// ErrSourceTooLarge is returned when the source code archive exceeds
+// MaxSourceSize.
+const ErrSourceTooLarge errors.Error = "source archive exceeds the size limit of AMO"
+
+// ErrNoReadme is returned when the source code archive has no README with the
+// build instructions, which AMO reviewers require.
+const ErrNoReadme errors.Error = "source archive has no README with build instructions for reviewers"
+
+// SourceArchiveConfig describes the source code archive built from a
+// directory.
+type SourceArchiveConfig struct {
+	// Logger is used to log the progress of building the archive.
+	Logger *slog.Logger
+
+	// Dir is the directory with the source code.  Files ignored by the
+	// .gitignore files in it and the .git directory are never included.
+	Dir string
+
+	// Readme is the path to the file with the build instructions for
+	// reviewers.  It's added to the root of the archive under its own name,
+	// replacing the file with the same name from Dir.  Either it or a README
+	// in the root of Dir is required.
+	Readme string
+
+	// Include contains the patterns in the .gitignore format relative to Dir.
+	// If not empty, only the matching files are included.
+	Include []string
+
+	// Exclude contains the patterns in the .gitignore format relative to Dir
+	// of the files excluded in addition to the ignored ones.
+	Exclude []string
+}
+
+// BuildSourceArchive builds the zip archive with the source code described by
+// conf and atomically writes it to output.  It returns an error wrapping
+// ErrSourceTooLarge if the archive exceeds MaxSourceSize and an error wrapping
+// ErrNoReadme if there are no build instructions.
+func BuildSourceArchive(conf SourceArchiveConfig, output string) (err error) {
+	l := conf.Logger.With("action", "BuildSourceArchive", "dir", conf.Dir)
+	l.Debug("building source archive")
+
+	entries, err := sourceEntries(conf, output)
+	if err != nil {
+		return fmt.Errorf("collecting source files: %w", err)
+	}
+
+	if !hasReadme(entries) {
+		return fmt.Errorf("%w: add one to %q or set it with --source-readme", ErrNoReadme, conf.Dir)
+	}
+
+	var size int64
+	err = fileutil.WriteFileAtomic(output, 0o644, func(f *os.File) (err error) {
+		lw := &limitWriter{w: f, limit: MaxSourceSize}
+		err = fileutil.WriteZip(lw, entries)
+		size = lw.n
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("writing source archive %q: %w", output, err)
+	}
+
+	l.Info("source archive built", "output", output, "files", len(entries), "size", size)
+
+	return nil
+}
+
+// sourceEntries walks conf.Dir and returns the archive entries for the files
+// that aren't ignored, excluded or filtered out by the include patterns.  The
+// output file is skipped if it's inside the directory.
+func sourceEntries(conf SourceArchiveConfig, output string) (entries []fileutil.ZipEntry, err error) {
+	root := filepath.Clean(conf.Dir)
+
+	absOutput, err := filepath.Abs(output)
+	if err != nil {
+		return nil, fmt.Errorf("getting absolute output path: %w", err)
+	}
+
+	include := &fileutil.Ignore{}
+	for _, p := range conf.Include {
+		include.Add("", p)
+	}
+
+	readmeName := ""
+	if conf.Readme != "" {
+		readmeName = filepath.Base(conf.Readme)
+	}
+
+	ignore := &fileutil.Ignore{}
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return fmt.Errorf("getting relative path: %w", err)
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			return walkSourceDir(ignore, conf.Exclude, p, rel, d.Name())
+		}
+
+		if !d.Type().IsRegular() || ignore.Match(rel, false) {
+			return nil
+		}
+
+		if len(conf.Include) > 0 && !include.Match(rel, false) {
+			return nil
+		}
+
+		if rel == readmeName || isSamePath(p, absOutput) {
+			return nil
+		}
+
+		entries = append(entries, fileutil.ZipEntry{Name: rel, Path: p})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.Readme != "" {
+		entries = append(entries, fileutil.ZipEntry{Name: readmeName, Path: conf.Readme})
+	}
+
+	return entries, nil
+}
+
+// walkSourceDir decides whether the directory with the slash-separated path
+// rel relative to the source root should be walked and, if so, adds the
+// patterns of its ignore file.  The exclude patterns are added after the ones
+// of the root ignore file, so that they take precedence.
+func walkSourceDir(ignore *fileutil.Ignore, exclude []string, dirPath, rel, name string) (err error) {
+	if rel == "." {
+		rel = ""
+	} else if name == ".git" || ignore.Match(rel, true) {
+		return filepath.SkipDir
+	}
+
+	err = ignore.AddFile(rel, filepath.Join(dirPath, fileutil.IgnoreFilename))
+	if err != nil {
+		return err
+	}
+
+	if rel == "" {
+		for _, p := range exclude {
+			ignore.Add("", p)
+		}
+	}
+
+	return nil
+}
+
+// isSamePath returns true if p points to the same path as the absolute path
+// absPath.
+func isSamePath(p, absPath string) (ok bool) {
+	abs, err := filepath.Abs(p)
+
+	return err == nil && abs == absPath
+}
+
+// hasReadme returns true if there is a README file in the root of the archive.
+func hasReadme(entries []fileutil.ZipEntry) (ok bool) {
+	for _, e := range entries {
+		if !strings.Contains(e.Name, "/") && strings.HasPrefix(strings.ToUpper(path.Base(e.Name)), "README") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkSourceSize returns an error if the source archive at sourcepath exceeds
+// MaxSourceSize.  It's called before uploading the extension, so that the
+// error is reported early.  Empty sourcepath means there is no source archive.
+func checkSourceSize(sourcepath string) (err error) {
+	if sourcepath == "" {
+		return nil
+	}
+
+	fi, err := os.Stat(filepath.Clean(sourcepath))
+	if err != nil {
+		return fmt.Errorf("getting source archive info: %w", err)
+	}
+
+	if fi.Size() > MaxSourceSize {
+		return fmt.Errorf("%w: %q is %d bytes, maximum is %d", ErrSourceTooLarge, sourcepath, fi.Size(), MaxSourceSize)
+	}
+
+	return nil
+}
+
+// limitWriter is an io.Writer that fails with ErrSourceTooLarge once more than
+// limit bytes are written.
+type limitWriter struct {
+	w     io.Writer
+	n     int64
+	limit int64
+}
+
+// type check
+var _ io.Writer = (*limitWriter)(nil)
+
+// Write implements the io.Writer interface for *limitWriter.
+func (w *limitWriter) Write(p []byte) (n int, err error) {
+	if w.n+int64(len(p)) > w.limit {
+		return 0, fmt.Errorf("%w: maximum is %d bytes", ErrSourceTooLarge, w.limit)
+	}
+
+	n, err = w.w.Write(p)
+	w.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,116 @@
+package firefox_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/adguardteam/go-webext/internal/fileutil"
+	"github.com/adguardteam/go-webext/internal/firefox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestFiles creates files with the slash-separated names relative to dir.
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o750))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+	}
+}
+
+// zipNames returns the names of the files in the zip archive.
+func zipNames(t *testing.T, path string) (names []string) {
+	t.Helper()
+
+	r, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, r.Close()) })
+
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+
+	return names
+}
+
+func TestBuildSourceArchive(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		".gitignore":              "node_modules/\n*.log\n",
+		".git/HEAD":               "ref: refs/heads/master\n",
+		"README.md":               "original readme",
+		"package.json":            "{}",
+		"debug.log":               "log",
+		"node_modules/a/index.js": "module",
+		"src/index.js":            "code",
+		"src/.gitignore":          "generated/\n",
+		"src/generated/out.js":    "generated",
+		"src/secret.key":          "secret",
+		"tests/index.test.js":     "test",
+	})
+
+	readme := filepath.Join(t.TempDir(), "README.md")
+	require.NoError(t, os.WriteFile(readme, []byte("build instructions"), 0o600))
+
+	t.Run("exclude", func(t *testing.T) {
+		output := filepath.Join(dir, "source.zip")
+		err := firefox.BuildSourceArchive(firefox.SourceArchiveConfig{
+			Logger:  slogutil.NewDiscardLogger(),
+			Dir:     dir,
+			Readme:  readme,
+			Exclude: []string{"*.key"},
+		}, output)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, os.Remove(output)) })
+
+		assert.ElementsMatch(t, []string{
+			".gitignore",
+			"README.md",
+			"package.json",
+			"src/.gitignore",
+			"src/index.js",
+			"tests/index.test.js",
+		}, zipNames(t, output))
+
+		content, err := fileutil.ReadFileFromZip(output, "README.md")
+		require.NoError(t, err)
+		assert.Equal(t, "build instructions", string(content))
+	})
+
+	t.Run("include", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "source.zip")
+		err := firefox.BuildSourceArchive(firefox.SourceArchiveConfig{
+			Logger:  slogutil.NewDiscardLogger(),
+			Dir:     dir,
+			Readme:  readme,
+			Include: []string{"/src/", "package.json"},
+		}, output)
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []string{
+			"README.md",
+			"package.json",
+			"src/.gitignore",
+			"src/index.js",
+			"src/secret.key",
+		}, zipNames(t, output))
+	})
+
+	t.Run("no_readme", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "source.zip")
+		err := firefox.BuildSourceArchive(firefox.SourceArchiveConfig{
+			Logger:  slogutil.NewDiscardLogger(),
+			Dir:     dir,
+			Include: []string{"/src/"},
+		}, output)
+		require.ErrorIs(t, err, firefox.ErrNoReadme)
+
+		assert.NoFileExists(t, output)
+	})
+}
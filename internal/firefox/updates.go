@@ -0,0 +1,421 @@
+package firefox
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/adguardteam/go-webext/internal/fileutil"
+)
+
+// UpdateLinkVersionPlaceholder is replaced with the extension version in the
+// update link of the update manifest entry.
+const UpdateLinkVersionPlaceholder = "{version}"
+
+// UpdateManifest describes the update manifest used by self-distributed
+// extensions, usually named updates.json.
+// https://extensionworkshop.com/documentation/manage/updating-your-extension/
+type UpdateManifest struct {
+	Addons map[string]*UpdateManifestAddon `json:"addons"`
+
+	// Extra contains the fields unknown to this package, which are preserved
+	// when the manifest is written back.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UpdateManifestAddon describes the updates of a single extension in the
+// update manifest.
+type UpdateManifestAddon struct {
+	Updates []*UpdateManifestEntry `json:"updates"`
+
+	// Extra contains the fields unknown to this package, which are preserved
+	// when the manifest is written back.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UpdateManifestEntry describes a single version in the update manifest.
+type UpdateManifestEntry struct {
+	Version       string                     `json:"version"`
+	UpdateLink    string                     `json:"update_link"`
+	UpdateHash    string                     `json:"update_hash,omitempty"`
+	UpdateInfoURL string                     `json:"update_info_url,omitempty"`
+	Applications  *UpdateManifestApplication `json:"applications,omitempty"`
+
+	// Extra contains the fields unknown to this package, which are preserved
+	// when the manifest is written back.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UpdateManifestApplication describes the application compatibility of the
+// version in the update manifest.
+type UpdateManifestApplication struct {
+	Gecko UpdateManifestGecko `json:"gecko"`
+
+	// Extra contains the fields unknown to this package, which are preserved
+	// when the manifest is written back.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UpdateManifestGecko describes the Firefox compatibility of the version in the
+// update manifest.
+type UpdateManifestGecko struct {
+	StrictMinVersion string `json:"strict_min_version,omitempty"`
+	StrictMaxVersion string `json:"strict_max_version,omitempty"`
+
+	// Extra contains the fields unknown to this package, which are preserved
+	// when the manifest is written back.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// NewUpdateManifestEntry creates the update manifest entry for the signed
+// package at xpiPath.  The version and the minimum Firefox version are read
+// from the package manifest, the update hash is computed from the package
+// itself.  UpdateLinkVersionPlaceholder in updateLink is replaced with the
+// version.  It also returns the ID of the extension.
+func NewUpdateManifestEntry(xpiPath, updateLink string) (appID string, entry *UpdateManifestEntry, err error) {
+	extData, err := extDataFromFile(xpiPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("getting extension data: %q due to: %w", xpiPath, err)
+	}
+
+	hash, err := fileSHA256(xpiPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("computing update hash: %w", err)
+	}
+
+	entry = &UpdateManifestEntry{
+		Version:    extData.version,
+		UpdateLink: strings.ReplaceAll(updateLink, UpdateLinkVersionPlaceholder, extData.version),
+		UpdateHash: "sha256:" + hash,
+	}
+
+	if extData.strictMinVersion != "" {
+		entry.Applications = &UpdateManifestApplication{
+			Gecko: UpdateManifestGecko{
+				StrictMinVersion: extData.strictMinVersion,
+			},
+		}
+	}
+
+	return extData.appID, entry, nil
+}
+
+// fileSHA256 returns the hex-encoded SHA-256 checksum of the file.
+func fileSHA256(path string) (sum string, err error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("opening file: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, f.Close()) }()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", fmt.Errorf("reading file: %w", err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Set adds the entry to the updates of the extension with appID.  If there is
+// already an entry with the same version, it's replaced, keeping the unknown
+// fields of the old entry that entry doesn't set.
+func (m *UpdateManifest) Set(appID string, entry *UpdateManifestEntry) {
+	if m.Addons == nil {
+		m.Addons = map[string]*UpdateManifestAddon{}
+	}
+
+	addon, ok := m.Addons[appID]
+	if !ok {
+		addon = &UpdateManifestAddon{}
+		m.Addons[appID] = addon
+	}
+
+	for i, e := range addon.Updates {
+		if e.Version == entry.Version {
+			entry.mergeExtra(e)
+			addon.Updates[i] = entry
+
+			return
+		}
+	}
+
+	addon.Updates = append(addon.Updates, entry)
+}
+
+// mergeExtra adds the unknown fields of old, including the ones of its
+// applications, missing from e.
+func (e *UpdateManifestEntry) mergeExtra(old *UpdateManifestEntry) {
+	e.Extra = mergeExtra(e.Extra, old.Extra)
+
+	if e.Applications == nil || old.Applications == nil {
+		return
+	}
+
+	e.Applications.Extra = mergeExtra(e.Applications.Extra, old.Applications.Extra)
+	e.Applications.Gecko.Extra = mergeExtra(e.Applications.Gecko.Extra, old.Applications.Gecko.Extra)
+}
+
+// mergeExtra returns extra with the fields of old it doesn't have.
+func mergeExtra(extra, old map[string]json.RawMessage) (merged map[string]json.RawMessage) {
+	if len(old) == 0 {
+		return extra
+	}
+
+	merged = maps.Clone(old)
+	maps.Copy(merged, extra)
+
+	return merged
+}
+
+// ReadUpdateManifest reads the update manifest from path.  If the file doesn't
+// exist, an empty manifest is returned.
+func ReadUpdateManifest(path string) (m *UpdateManifest, err error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return &UpdateManifest{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading update manifest: %w", err)
+	}
+
+	m = &UpdateManifest{}
+	err = json.Unmarshal(data, m)
+	if err != nil {
+		return nil, fmt.Errorf("can't unmarshal update manifest %q due to: %w", path, err)
+	}
+
+	return m, nil
+}
+
+// Write atomically writes the update manifest to path.
+func (m *UpdateManifest) Write(path string) (err error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling update manifest: %w", err)
+	}
+
+	data = append(data, '\n')
+
+	return fileutil.WriteFileAtomic(path, 0o644, func(f *os.File) (err error) {
+		_, err = f.Write(data)
+
+		return err
+	})
+}
+
+// AddToUpdateManifest creates the update manifest entry for the signed package
+// at xpiPath and merges it into the update manifest at manifestPath, creating
+// the file if needed.  See NewUpdateManifestEntry for the updateLink format.
+func AddToUpdateManifest(manifestPath, xpiPath, updateLink string) (entry *UpdateManifestEntry, err error) {
+	appID, entry, err := NewUpdateManifestEntry(xpiPath, updateLink)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := ReadUpdateManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Set(appID, entry)
+
+	err = m.Write(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("writing update manifest: %w", err)
+	}
+
+	return entry, nil
+}
+
+// type check
+var (
+	_ json.Marshaler   = UpdateManifest{}
+	_ json.Unmarshaler = (*UpdateManifest)(nil)
+)
+
+// MarshalJSON implements the json.Marshaler interface for UpdateManifest.
+func (m UpdateManifest) MarshalJSON() (data []byte, err error) {
+	type plain UpdateManifest
+
+	return marshalWithExtra((*plain)(&m), m.Extra)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for *UpdateManifest.
+func (m *UpdateManifest) UnmarshalJSON(data []byte) (err error) {
+	type plain UpdateManifest
+
+	m.Extra, err = unmarshalWithExtra(data, (*plain)(m))
+
+	return err
+}
+
+// type check
+var (
+	_ json.Marshaler   = UpdateManifestAddon{}
+	_ json.Unmarshaler = (*UpdateManifestAddon)(nil)
+)
+
+// MarshalJSON implements the json.Marshaler interface for UpdateManifestAddon.
+func (a UpdateManifestAddon) MarshalJSON() (data []byte, err error) {
+	type plain UpdateManifestAddon
+
+	return marshalWithExtra((*plain)(&a), a.Extra)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for *UpdateManifestAddon.
+func (a *UpdateManifestAddon) UnmarshalJSON(data []byte) (err error) {
+	type plain UpdateManifestAddon
+
+	a.Extra, err = unmarshalWithExtra(data, (*plain)(a))
+
+	return err
+}
+
+// type check
+var (
+	_ json.Marshaler   = UpdateManifestEntry{}
+	_ json.Unmarshaler = (*UpdateManifestEntry)(nil)
+)
+
+// MarshalJSON implements the json.Marshaler interface for UpdateManifestEntry.
+func (e UpdateManifestEntry) MarshalJSON() (data []byte, err error) {
+	type plain UpdateManifestEntry
+
+	return marshalWithExtra((*plain)(&e), e.Extra)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for *UpdateManifestEntry.
+func (e *UpdateManifestEntry) UnmarshalJSON(data []byte) (err error) {
+	type plain UpdateManifestEntry
+
+	e.Extra, err = unmarshalWithExtra(data, (*plain)(e))
+
+	return err
+}
+
+// type check
+var (
+	_ json.Marshaler   = UpdateManifestApplication{}
+	_ json.Unmarshaler = (*UpdateManifestApplication)(nil)
+)
+
+// MarshalJSON implements the json.Marshaler interface for UpdateManifestApplication.
+func (a UpdateManifestApplication) MarshalJSON() (data []byte, err error) {
+	type plain UpdateManifestApplication
+
+	return marshalWithExtra((*plain)(&a), a.Extra)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for *UpdateManifestApplication.
+func (a *UpdateManifestApplication) UnmarshalJSON(data []byte) (err error) {
+	type plain UpdateManifestApplication
+
+	a.Extra, err = unmarshalWithExtra(data, (*plain)(a))
+
+	return err
+}
+
+// type check
+var (
+	_ json.Marshaler   = UpdateManifestGecko{}
+	_ json.Unmarshaler = (*UpdateManifestGecko)(nil)
+)
+
+// MarshalJSON implements the json.Marshaler interface for UpdateManifestGecko.
+func (g UpdateManifestGecko) MarshalJSON() (data []byte, err error) {
+	type plain UpdateManifestGecko
+
+	return marshalWithExtra((*plain)(&g), g.Extra)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for *UpdateManifestGecko.
+func (g *UpdateManifestGecko) UnmarshalJSON(data []byte) (err error) {
+	type plain UpdateManifestGecko
+
+	g.Extra, err = unmarshalWithExtra(data, (*plain)(g))
+
+	return err
+}
+
+// marshalWithExtra returns the JSON encoding of the struct pointed to by v
+// with the extra fields appended, unless v has the fields with the same names.
+func marshalWithExtra(v any, extra map[string]json.RawMessage) (data []byte, err error) {
+	data, err = json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v).Elem())
+
+	buf := bytes.NewBuffer(bytes.TrimSuffix(data, []byte("}")))
+	for _, k := range slices.Sorted(maps.Keys(extra)) {
+		if known[k] {
+			continue
+		}
+
+		var key []byte
+		key, err = json.Marshal(k)
+		if err != nil {
+			return nil, fmt.Errorf("encoding field %q: %w", k, err)
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(extra[k])
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// unmarshalWithExtra decodes the JSON object into the struct pointed to by v
+// and returns the fields unknown to the struct.
+func unmarshalWithExtra(data []byte, v any) (extra map[string]json.RawMessage, err error) {
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &extra)
+	if err != nil {
+		return nil, err
+	}
+
+	for k := range jsonFieldNames(reflect.TypeOf(v).Elem()) {
+		delete(extra, k)
+	}
+
+	if len(extra) == 0 {
+		return nil, nil
+	}
+
+	return extra, nil
+}
+
+// jsonFieldNames returns the names of the JSON fields of the struct type t.
+func jsonFieldNames(t reflect.Type) (names map[string]bool) {
+	names = map[string]bool{}
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,158 @@
+package firefox_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adguardteam/go-webext/internal/firefox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddToUpdateManifest(t *testing.T) {
+	const updateLink = "https://example.org/ext-" + firefox.UpdateLinkVersionPlaceholder + ".xpi"
+
+	manifestPath := filepath.Join(t.TempDir(), "updates.json")
+
+	// Prepare the manifest with an older version of the extension and
+	// another extension.
+	existing := &firefox.UpdateManifest{}
+	existing.Set(testAppID, &firefox.UpdateManifestEntry{
+		Version:    "0.0.2",
+		UpdateLink: "https://example.org/ext-0.0.2.xpi",
+	})
+	existing.Set("other@example.org", &firefox.UpdateManifestEntry{
+		Version:    "1.0.0",
+		UpdateLink: "https://example.org/other-1.0.0.xpi",
+	})
+	require.NoError(t, existing.Write(manifestPath))
+
+	entry, err := firefox.AddToUpdateManifest(manifestPath, testFilepath, updateLink)
+	require.NoError(t, err)
+
+	assert.Equal(t, testVersion, entry.Version)
+	assert.Equal(t, "https://example.org/ext-0.0.3.xpi", entry.UpdateLink)
+	assert.True(t, strings.HasPrefix(entry.UpdateHash, "sha256:"))
+	require.NotNil(t, entry.Applications)
+	assert.Equal(t, "78.0", entry.Applications.Gecko.StrictMinVersion)
+
+	// Adding the same version again must replace the entry.
+	_, err = firefox.AddToUpdateManifest(manifestPath, testFilepath, updateLink)
+	require.NoError(t, err)
+
+	m, err := firefox.ReadUpdateManifest(manifestPath)
+	require.NoError(t, err)
+
+	require.Len(t, m.Addons, 2)
+	require.Len(t, m.Addons[testAppID].Updates, 2)
+	assert.Equal(t, "0.0.2", m.Addons[testAppID].Updates[0].Version)
+	assert.Equal(t, entry, m.Addons[testAppID].Updates[1])
+	assert.Len(t, m.Addons["other@example.org"].Updates, 1)
+}
+
+func TestUpdateManifest_Set_keepExtra(t *testing.T) {
+	m := &firefox.UpdateManifest{}
+	m.Set(testAppID, &firefox.UpdateManifestEntry{
+		Version:    "1.0.0",
+		UpdateLink: "https://example.org/ext-old.xpi",
+		Applications: &firefox.UpdateManifestApplication{
+			Gecko: firefox.UpdateManifestGecko{
+				StrictMinVersion: "78.0",
+				Extra: map[string]json.RawMessage{
+					"advisory_max_version": json.RawMessage(`"*"`),
+				},
+			},
+		},
+		Extra: map[string]json.RawMessage{
+			"custom": json.RawMessage(`"old"`),
+			"note":   json.RawMessage(`"kept"`),
+		},
+	})
+
+	entry := &firefox.UpdateManifestEntry{
+		Version:    "1.0.0",
+		UpdateLink: "https://example.org/ext-new.xpi",
+		Applications: &firefox.UpdateManifestApplication{
+			Gecko: firefox.UpdateManifestGecko{
+				StrictMinVersion: "91.0",
+			},
+		},
+		Extra: map[string]json.RawMessage{
+			"custom": json.RawMessage(`"new"`),
+		},
+	}
+	m.Set(testAppID, entry)
+
+	require.Len(t, m.Addons[testAppID].Updates, 1)
+
+	got := m.Addons[testAppID].Updates[0]
+	assert.Equal(t, "https://example.org/ext-new.xpi", got.UpdateLink)
+	assert.Equal(t, map[string]json.RawMessage{
+		"custom": json.RawMessage(`"new"`),
+		"note":   json.RawMessage(`"kept"`),
+	}, got.Extra)
+
+	require.NotNil(t, got.Applications)
+	assert.Equal(t, "91.0", got.Applications.Gecko.StrictMinVersion)
+	assert.Equal(t, map[string]json.RawMessage{
+		"advisory_max_version": json.RawMessage(`"*"`),
+	}, got.Applications.Gecko.Extra)
+}
+
+func TestReadUpdateManifest_notExist(t *testing.T) {
+	m, err := firefox.ReadUpdateManifest(filepath.Join(t.TempDir(), "updates.json"))
+	require.NoError(t, err)
+
+	assert.Empty(t, m.Addons)
+}
+
+func TestUpdateManifest_roundTrip(t *testing.T) {
+	const data = `{
+  "addons": {
+    "other@example.org": {
+      "updates": [
+        {
+          "version": "1.0.0",
+          "update_link": "https://example.org/other-1.0.0.xpi",
+          "applications": {
+            "gecko": {
+              "strict_min_version": "78.0",
+              "advisory_max_version": "130.*"
+            },
+            "android": {"strict_min_version": "120.0"}
+          },
+          "browser_specific_settings": {"gecko": {"strict_min_version": "78.0"}}
+        }
+      ],
+      "x-channel": "beta"
+    }
+  },
+  "$schema": "https://example.org/updates.schema.json"
+}`
+
+	manifestPath := filepath.Join(t.TempDir(), "updates.json")
+	require.NoError(t, os.WriteFile(manifestPath, []byte(data), 0o600))
+
+	m, err := firefox.ReadUpdateManifest(manifestPath)
+	require.NoError(t, err)
+	require.NoError(t, m.Write(manifestPath))
+
+	written, err := os.ReadFile(manifestPath)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, data, string(written))
+
+	// Adding a version keeps the unknown fields too.
+	_, err = firefox.AddToUpdateManifest(manifestPath, testFilepath, "https://example.org/ext.xpi")
+	require.NoError(t, err)
+
+	m, err = firefox.ReadUpdateManifest(manifestPath)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `"https://example.org/updates.schema.json"`, string(m.Extra["$schema"]))
+	assert.JSONEq(t, `"beta"`, string(m.Addons["other@example.org"].Extra["x-channel"]))
+	require.Len(t, m.Addons[testAppID].Updates, 1)
+}
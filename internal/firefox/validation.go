@@ -0,0 +1,209 @@
+package firefox
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MessageType is the severity of a validation message reported by the AMO
+// linter.
+type MessageType string
+
+const (
+	// MessageTypeError is a message that makes the upload invalid.
+	MessageTypeError MessageType = "error"
+	// MessageTypeWarning is a message that doesn't block the upload but may
+	// cause issues during the review.
+	MessageTypeWarning MessageType = "warning"
+	// MessageTypeNotice is an informational message.
+	MessageTypeNotice MessageType = "notice"
+)
+
+// MessageDescription is a description of the validation message.  AMO returns
+// it either as a string or as a list of strings, so both forms are decoded
+// into a list.
+type MessageDescription []string
+
+// UnmarshalJSON implements the json.Unmarshaler interface for
+// MessageDescription.
+func (d *MessageDescription) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*d = lines
+
+		return nil
+	}
+
+	var line string
+	if err := json.Unmarshal(data, &line); err != nil {
+		return fmt.Errorf("can't unmarshal message description: %w", err)
+	}
+
+	if line == "" {
+		*d = nil
+	} else {
+		*d = MessageDescription{line}
+	}
+
+	return nil
+}
+
+// ValidationMessage describes a single error, warning or notice reported by
+// the AMO linter.
+type ValidationMessage struct {
+	ID          []string           `json:"id"`
+	Type        MessageType        `json:"type"`
+	Message     string             `json:"message"`
+	Description MessageDescription `json:"description"`
+	File        string             `json:"file"`
+	Line        int                `json:"line"`
+	Column      int                `json:"column"`
+}
+
+// Location returns the position of the message in the extension package in
+// the "file:line:column" form.  Empty parts are omitted.
+func (m ValidationMessage) Location() string {
+	if m.File == "" {
+		return ""
+	}
+
+	switch {
+	case m.Line > 0 && m.Column > 0:
+		return fmt.Sprintf("%s:%d:%d", m.File, m.Line, m.Column)
+	case m.Line > 0:
+		return fmt.Sprintf("%s:%d", m.File, m.Line)
+	default:
+		return m.File
+	}
+}
+
+// Code returns the linter code of the message, e.g. "MANIFEST_FIELD_INVALID".
+func (m ValidationMessage) Code() string {
+	return strings.Join(m.ID, "/")
+}
+
+// Validation describes the results of the upload validation.
+// Actually the structure is bigger, but we don't need it.
+// https://addons-server.readthedocs.io/en/latest/topics/api/addons.html#upload-detail
+type Validation struct {
+	Success  bool                `json:"success"`
+	Errors   int                 `json:"errors"`
+	Warnings int                 `json:"warnings"`
+	Notices  int                 `json:"notices"`
+	Messages []ValidationMessage `json:"messages"`
+}
+
+// WriteText writes a human-readable validation report to w.
+func (v *Validation) WriteText(w io.Writer) (err error) {
+	_, err = fmt.Fprintf(
+		w,
+		"Validation: %d error(s), %d warning(s), %d notice(s)\n",
+		v.Errors,
+		v.Warnings,
+		v.Notices,
+	)
+	if err != nil {
+		return fmt.Errorf("writing summary: %w", err)
+	}
+
+	for _, m := range v.Messages {
+		err = writeTextMessage(w, m)
+		if err != nil {
+			return fmt.Errorf("writing message: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeTextMessage writes a single validation message to w.
+func writeTextMessage(w io.Writer, m ValidationMessage) (err error) {
+	header := strings.ToUpper(string(m.Type))
+	if loc := m.Location(); loc != "" {
+		header += " " + loc
+	}
+
+	if code := m.Code(); code != "" {
+		header += " [" + code + "]"
+	}
+
+	_, err = fmt.Fprintf(w, "\n%s\n    %s\n", header, m.Message)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range m.Description {
+		_, err = fmt.Fprintf(w, "    %s\n", line)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteJSON writes the validation report to w as indented JSON.
+func (v *Validation) WriteJSON(w io.Writer) (err error) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+
+	err = enc.Encode(v)
+	if err != nil {
+		return fmt.Errorf("encoding validation: %w", err)
+	}
+
+	return nil
+}
+
+// ValidationError is returned when the upload doesn't pass the AMO validation.
+type ValidationError struct {
+	// Validation contains the validation results, it may be nil if AMO didn't
+	// return them.
+	Validation *Validation
+	// URL is the URL of the upload.
+	URL string
+	// Warnings is true if the upload is valid, but it was rejected because of
+	// the warnings.
+	Warnings bool
+}
+
+// type check
+var _ error = (*ValidationError)(nil)
+
+// Error implements the error interface for *ValidationError.
+func (e *ValidationError) Error() (msg string) {
+	if e.Validation == nil {
+		return fmt.Sprintf("not valid, validation url: %s", e.URL)
+	}
+
+	if e.Warnings {
+		return fmt.Sprintf("validation reported %d warning(s), validation url: %s", e.Validation.Warnings, e.URL)
+	}
+
+	return fmt.Sprintf("not valid, %d error(s), validation url: %s", e.Validation.Errors, e.URL)
+}
+
+// checkValidation returns a *ValidationError if the processed upload should be
+// rejected.  If failOnWarnings is true, uploads with warnings are rejected as
+// well.
+func checkValidation(uploadDetail *UploadDetail, failOnWarnings bool) (err error) {
+	if !uploadDetail.Valid {
+		return &ValidationError{
+			Validation: uploadDetail.Validation,
+			URL:        uploadDetail.URL,
+		}
+	}
+
+	v := uploadDetail.Validation
+	if failOnWarnings && v != nil && v.Warnings > 0 {
+		return &ValidationError{
+			Validation: v,
+			URL:        uploadDetail.URL,
+			Warnings:   true,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package firefox_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/adguardteam/go-webext/internal/firefox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testValidationJSON = `{
+	"success": false,
+	"errors": 1,
+	"warnings": 1,
+	"notices": 0,
+	"messages": [{
+		"id": ["MANIFEST_FIELD_INVALID"],
+		"type": "error",
+		"message": "\"/version\" must match format \"versionString\"",
+		"description": "Your JSON file could not be parsed.",
+		"file": "manifest.json",
+		"line": 3,
+		"column": 14
+	}, {
+		"id": ["UNSAFE_VAR_ASSIGNMENT"],
+		"type": "warning",
+		"message": "Unsafe assignment to innerHTML",
+		"description": ["Due to both security and performance concerns,", "this may not be set."],
+		"file": "content.js",
+		"line": null,
+		"column": null
+	}]
+}`
+
+func TestValidation_UnmarshalJSON(t *testing.T) {
+	v := &firefox.Validation{}
+	err := json.Unmarshal([]byte(testValidationJSON), v)
+	require.NoError(t, err)
+
+	require.Len(t, v.Messages, 2)
+
+	assert.Equal(t, firefox.MessageTypeError, v.Messages[0].Type)
+	assert.Equal(t, firefox.MessageDescription{"Your JSON file could not be parsed."}, v.Messages[0].Description)
+	assert.Equal(t, "manifest.json:3:14", v.Messages[0].Location())
+	assert.Equal(t, "MANIFEST_FIELD_INVALID", v.Messages[0].Code())
+
+	assert.Equal(t, firefox.MessageTypeWarning, v.Messages[1].Type)
+	assert.Len(t, v.Messages[1].Description, 2)
+	assert.Equal(t, "content.js", v.Messages[1].Location())
+}
+
+func TestValidation_WriteText(t *testing.T) {
+	v := &firefox.Validation{}
+	err := json.Unmarshal([]byte(testValidationJSON), v)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	err = v.WriteText(buf)
+	require.NoError(t, err)
+
+	report := buf.String()
+	assert.Contains(t, report, "Validation: 1 error(s), 1 warning(s), 0 notice(s)")
+	assert.Contains(t, report, "ERROR manifest.json:3:14 [MANIFEST_FIELD_INVALID]")
+	assert.Contains(t, report, "WARNING content.js [UNSAFE_VAR_ASSIGNMENT]")
+	assert.Contains(t, report, "this may not be set.")
+}
+
+func TestValidationError_Error(t *testing.T) {
+	testCases := []struct {
+		err  *firefox.ValidationError
+		name string
+		want string
+	}{{
+		err:  &firefox.ValidationError{Validation: &firefox.Validation{Errors: 2}, URL: testURL},
+		name: "errors",
+		want: "not valid, 2 error(s), validation url: " + testURL,
+	}, {
+		err:  &firefox.ValidationError{Validation: &firefox.Validation{Warnings: 3}, URL: testURL, Warnings: true},
+		name: "warnings",
+		want: "validation reported 3 warning(s), validation url: " + testURL,
+	}, {
+		err:  &firefox.ValidationError{URL: testURL, Warnings: true},
+		name: "no_validation",
+		want: "not valid, validation url: " + testURL,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.err.Error())
+		})
+	}
+}
+
+func TestUpdate_validation(t *testing.T) {
+	newStore := func(t *testing.T, detail *firefox.UploadDetail, failOnWarnings bool) *firefox.Store {
+		t.Helper()
+
+		mockAPI := &MockAPI{
+			onCreateUpload: func(_ io.Reader, _ firefox.Channel) (*firefox.UploadDetail, error) {
+				return &firefox.UploadDetail{UUID: testUUID}, nil
+			},
+			onUploadDetail: func(_ string) (*firefox.UploadDetail, error) {
+				return detail, nil
+			},
+			onCreateVersion: func(_, _, _ string) (*firefox.VersionInfo, error) {
+				return &firefox.VersionInfo{ID: testVersionID}, nil
+			},
+		}
+
+		return firefox.NewStore(firefox.StoreConfig{
+			API:            mockAPI,
+			Logger:         slogutil.NewDiscardLogger(),
+			FailOnWarnings: failOnWarnings,
+		})
+	}
+
+	warnings := &firefox.Validation{
+		Success:  true,
+		Warnings: 1,
+		Messages: []firefox.ValidationMessage{{
+			Type:    firefox.MessageTypeWarning,
+			Message: "test warning",
+		}},
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		store := newStore(t, &firefox.UploadDetail{
+			UUID:       testUUID,
+			Processed:  true,
+			Valid:      false,
+			Validation: &firefox.Validation{Errors: 1},
+		}, false)
+
+		err := store.Update(testFilepath, "", testChannel, "")
+
+		var validationErr *firefox.ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		assert.False(t, validationErr.Warnings)
+		assert.Equal(t, 1, validationErr.Validation.Errors)
+	})
+
+	t.Run("warnings", func(t *testing.T) {
+		store := newStore(t, &firefox.UploadDetail{
+			UUID:       testUUID,
+			Processed:  true,
+			Valid:      true,
+			Validation: warnings,
+		}, false)
+
+		err := store.Update(testFilepath, "", testChannel, "")
+		require.NoError(t, err)
+	})
+
+	t.Run("fail_on_warnings", func(t *testing.T) {
+		store := newStore(t, &firefox.UploadDetail{
+			UUID:       testUUID,
+			Processed:  true,
+			Valid:      true,
+			Validation: warnings,
+		}, true)
+
+		err := store.Update(testFilepath, "", testChannel, "")
+
+		var validationErr *firefox.ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		assert.True(t, validationErr.Warnings)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	expectedValidation := &firefox.Validation{
+		Success:  true,
+		Warnings: 1,
+	}
+
+	mockAPI := &MockAPI{
+		onCreateUpload: func(_ io.Reader, c firefox.Channel) (*firefox.UploadDetail, error) {
+			require.Equal(t, firefox.ChannelListed, c)
+
+			return &firefox.UploadDetail{UUID: testUUID}, nil
+		},
+		onUploadDetail: func(UUID string) (*firefox.UploadDetail, error) {
+			require.Equal(t, testUUID, UUID)
+
+			return &firefox.UploadDetail{
+				UUID:       testUUID,
+				Processed:  true,
+				Valid:      true,
+				Validation: expectedValidation,
+			}, nil
+		},
+		onCreateVersion: func(_, _, _ string) (*firefox.VersionInfo, error) {
+			panic("version must not be created")
+		},
+	}
+
+	store := firefox.NewStore(firefox.StoreConfig{
+		API:    mockAPI,
+		Logger: slogutil.NewDiscardLogger(),
+	})
+
+	validation, err := store.Validate(testFilepath, firefox.ChannelListed)
+	require.NoError(t, err)
+
+	assert.Equal(t, expectedValidation, validation)
+}
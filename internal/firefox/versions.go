@@ -0,0 +1,201 @@
+package firefox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// VersionFilter describes the filter for the versions list.  Empty fields
+// match any value.
+type VersionFilter struct {
+	// Channel is the channel of the version, either listed or unlisted.
+	Channel Channel
+	// Status is the status of the version file, e.g. "public".
+	Status string
+}
+
+// match returns true if the version matches the filter.
+func (f VersionFilter) match(v *VersionInfo) (ok bool) {
+	if f.Channel != "" && Channel(v.Channel) != f.Channel {
+		return false
+	}
+
+	if f.Status != "" && v.File.Status != f.Status {
+		return false
+	}
+
+	return true
+}
+
+// Versions returns all versions of the extension, both listed and unlisted,
+// that match the filter.
+func (s *Store) Versions(appID string, filter VersionFilter) (result []*VersionInfo, err error) {
+	l := s.logger.With("action", "Versions", "appID", appID)
+	l.Debug("retrieving versions", "channel", filter.Channel, "status", filter.Status)
+
+	versions, err := s.api.VersionsList(appID)
+	if err != nil {
+		return nil, fmt.Errorf("getting versions list for appID: %s, due to: %w", appID, err)
+	}
+
+	for _, v := range versions {
+		if filter.match(v) {
+			result = append(result, v)
+		}
+	}
+
+	l.Debug("versions retrieved", "total", len(versions), "matched", len(result))
+
+	return result, nil
+}
+
+// requireVersionID returns the ID of the version or an error if the extension
+// doesn't have such version.
+func (s *Store) requireVersionID(appID, version string) (versionID string, err error) {
+	versionID, err = s.getVersionID(appID, version)
+	if err != nil {
+		return "", err
+	}
+
+	if versionID == "" {
+		return "", fmt.Errorf("version %q of %q not found", version, appID)
+	}
+
+	return versionID, nil
+}
+
+// DisableVersion disables the version of the extension, so that it's no
+// longer available to users.
+func (s *Store) DisableVersion(appID, version string) (err error) {
+	return s.setVersionDisabled(appID, version, true)
+}
+
+// EnableVersion re-enables the version of the extension previously disabled
+// by the developer.
+func (s *Store) EnableVersion(appID, version string) (err error) {
+	return s.setVersionDisabled(appID, version, false)
+}
+
+// setVersionDisabled disables or re-enables the version of the extension.
+func (s *Store) setVersionDisabled(appID, version string, disabled bool) (err error) {
+	l := s.logger.With("action", "setVersionDisabled", "appID", appID, "version", version, "disabled", disabled)
+	l.Debug("changing version state")
+
+	versionID, err := s.requireVersionID(appID, version)
+	if err != nil {
+		return fmt.Errorf("getting version ID: %w", err)
+	}
+
+	versionInfo, err := s.api.SetVersionDisabled(appID, versionID, disabled)
+	if err != nil {
+		return fmt.Errorf("editing version %s: %w", versionID, err)
+	}
+
+	if versionInfo.IsDisabled != disabled {
+		return fmt.Errorf("version %s state wasn't changed, is_disabled: %t", versionID, versionInfo.IsDisabled)
+	}
+
+	l.Debug("version state changed")
+
+	return nil
+}
+
+// DeleteVersion deletes the version of the extension.  Only versions that
+// haven't been approved yet can be deleted.
+func (s *Store) DeleteVersion(appID, version string) (err error) {
+	l := s.logger.With("action", "DeleteVersion", "appID", appID, "version", version)
+	l.Debug("deleting version")
+
+	versionID, err := s.requireVersionID(appID, version)
+	if err != nil {
+		return fmt.Errorf("getting version ID: %w", err)
+	}
+
+	versionDetail, err := s.api.VersionDetail(appID, versionID)
+	if err != nil {
+		return fmt.Errorf("getting version detail for appID: %s, versionID: %s, due to: %w", appID, versionID, err)
+	}
+
+	if versionDetail.File.Status == FileStatusPublic {
+		return fmt.Errorf("version %q is already approved, disable it instead", version)
+	}
+
+	err = s.api.DeleteVersion(appID, versionID)
+	if err != nil {
+		return fmt.Errorf("deleting version %s: %w", versionID, err)
+	}
+
+	l.Debug("version deleted")
+
+	return nil
+}
+
+// Download downloads the signed package of the version of the extension and
+// saves it to output.
+func (s *Store) Download(appID, version, output string) (err error) {
+	l := s.logger.With("action", "Download", "appID", appID, "version", version)
+	l.Debug("downloading signed version")
+
+	versionID, err := s.requireVersionID(appID, version)
+	if err != nil {
+		return fmt.Errorf("getting version ID: %w", err)
+	}
+
+	isSigned, err := s.isSigned(appID, versionID)
+	if err != nil {
+		return fmt.Errorf("checking if extension is signed: %w", err)
+	}
+
+	if !isSigned {
+		return fmt.Errorf("version %q is not signed", version)
+	}
+
+	return s.downloadSigned(appID, versionID, output)
+}
+
+// DownloadAll downloads signed packages of all versions of the extension, both
+// listed and unlisted, to the outputDir.  The files are named after the
+// versions, e.g. "firefox-1.2.3.xpi".  It returns the paths of the downloaded
+// files.
+func (s *Store) DownloadAll(appID, outputDir string) (files []string, err error) {
+	l := s.logger.With("action", "DownloadAll", "appID", appID, "outputDir", outputDir)
+	l.Debug("downloading all signed versions")
+
+	versions, err := s.Versions(appID, VersionFilter{Status: FileStatusPublic})
+	if err != nil {
+		return nil, fmt.Errorf("getting signed versions: %w", err)
+	}
+
+	// The versions come from the server, so make sure they can't escape the
+	// output directory before downloading anything.
+	names := make([]string, len(versions))
+	for i, v := range versions {
+		names[i] = "firefox-" + v.Version + ".xpi"
+		if strings.ContainsAny(v.Version, `/\`) || filepath.Base(names[i]) != names[i] {
+			return nil, fmt.Errorf("version %q: invalid file name %q", v.Version, names[i])
+		}
+	}
+
+	err = os.MkdirAll(outputDir, 0o750)
+	if err != nil {
+		return nil, fmt.Errorf("creating output directory: %w", err)
+	}
+
+	for i, v := range versions {
+		output := filepath.Join(outputDir, names[i])
+
+		err = s.downloadSigned(appID, strconv.Itoa(v.ID), output)
+		if err != nil {
+			return files, fmt.Errorf("downloading version %q: %w", v.Version, err)
+		}
+
+		files = append(files, output)
+	}
+
+	l.Debug("signed versions downloaded", "count", len(files))
+
+	return files, nil
+}
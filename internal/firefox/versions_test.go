@@ -0,0 +1,211 @@
+package firefox_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/adguardteam/go-webext/internal/firefox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testVersions is a list of versions returned by the mock API.
+var testVersions = []*firefox.VersionInfo{{
+	ID:      1,
+	Version: "0.0.1",
+	Channel: string(firefox.ChannelListed),
+	File:    firefox.FileInfo{Status: firefox.FileStatusPublic},
+}, {
+	ID:      2,
+	Version: "0.0.2",
+	Channel: string(firefox.ChannelUnlisted),
+	File:    firefox.FileInfo{Status: firefox.FileStatusPublic},
+}, {
+	ID:      testVersionID,
+	Version: testVersion,
+	Channel: string(firefox.ChannelListed),
+	File:    firefox.FileInfo{Status: firefox.FileStatusNominated},
+}}
+
+func newVersionsStore(t *testing.T, mockAPI *MockAPI) *firefox.Store {
+	t.Helper()
+
+	mockAPI.onVersionsList = func(appID string) ([]*firefox.VersionInfo, error) {
+		require.Equal(t, testAppID, appID)
+
+		return testVersions, nil
+	}
+
+	return firefox.NewStore(firefox.StoreConfig{
+		API:    mockAPI,
+		Logger: slogutil.NewDiscardLogger(),
+	})
+}
+
+func TestVersions(t *testing.T) {
+	store := newVersionsStore(t, &MockAPI{})
+
+	testCases := []struct {
+		name   string
+		filter firefox.VersionFilter
+		want   []*firefox.VersionInfo
+	}{{
+		name:   "all",
+		filter: firefox.VersionFilter{},
+		want:   testVersions,
+	}, {
+		name:   "listed",
+		filter: firefox.VersionFilter{Channel: firefox.ChannelListed},
+		want:   []*firefox.VersionInfo{testVersions[0], testVersions[2]},
+	}, {
+		name: "unlisted_public",
+		filter: firefox.VersionFilter{
+			Channel: firefox.ChannelUnlisted,
+			Status:  firefox.FileStatusPublic,
+		},
+		want: []*firefox.VersionInfo{testVersions[1]},
+	}, {
+		name:   "no_match",
+		filter: firefox.VersionFilter{Status: firefox.FileStatusDisabled},
+		want:   nil,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			versions, err := store.Versions(testAppID, tc.filter)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want, versions)
+		})
+	}
+}
+
+func TestDisableVersion(t *testing.T) {
+	store := newVersionsStore(t, &MockAPI{
+		onSetVersionDisabled: func(appID, versionID string, disabled bool) (*firefox.VersionInfo, error) {
+			require.Equal(t, testAppID, appID)
+			require.Equal(t, "1", versionID)
+
+			return &firefox.VersionInfo{ID: 1, IsDisabled: disabled}, nil
+		},
+	})
+
+	err := store.DisableVersion(testAppID, "0.0.1")
+	require.NoError(t, err)
+
+	err = store.EnableVersion(testAppID, "0.0.1")
+	require.NoError(t, err)
+
+	err = store.DisableVersion(testAppID, "9.9.9")
+	require.Error(t, err)
+}
+
+func TestDeleteVersion(t *testing.T) {
+	var deletedID string
+
+	store := newVersionsStore(t, &MockAPI{
+		onVersionDetail: func(_, versionID string) (*firefox.VersionInfo, error) {
+			for _, v := range testVersions {
+				if strconv.Itoa(v.ID) == versionID {
+					return v, nil
+				}
+			}
+
+			panic("unexpected version id")
+		},
+		onDeleteVersion: func(appID, versionID string) error {
+			require.Equal(t, testAppID, appID)
+			deletedID = versionID
+
+			return nil
+		},
+	})
+
+	t.Run("unreviewed", func(t *testing.T) {
+		err := store.DeleteVersion(testAppID, testVersion)
+		require.NoError(t, err)
+
+		assert.Equal(t, strconv.Itoa(testVersionID), deletedID)
+	})
+
+	t.Run("approved", func(t *testing.T) {
+		deletedID = ""
+
+		err := store.DeleteVersion(testAppID, "0.0.1")
+		require.Error(t, err)
+
+		assert.Empty(t, deletedID)
+	})
+}
+
+func TestDownload(t *testing.T) {
+	mockAPI := &MockAPI{
+		onVersionDetail: func(_, versionID string) (*firefox.VersionInfo, error) {
+			for _, v := range testVersions {
+				if strconv.Itoa(v.ID) == versionID {
+					info := *v
+					info.File.URL = testURL + "?id=" + versionID
+
+					return &info, nil
+				}
+			}
+
+			panic("unexpected version id")
+		},
+		onDownloadSignedByURL: func(url string, w io.Writer) error {
+			_, err := io.WriteString(w, url)
+
+			return err
+		},
+	}
+	store := newVersionsStore(t, mockAPI)
+
+	t.Run("version", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "signed.xpi")
+
+		err := store.Download(testAppID, "0.0.2", output)
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(output)
+		require.NoError(t, err)
+
+		assert.Equal(t, testURL+"?id=2", string(content))
+	})
+
+	t.Run("not_signed", func(t *testing.T) {
+		err := store.Download(testAppID, testVersion, filepath.Join(t.TempDir(), "signed.xpi"))
+		require.Error(t, err)
+	})
+
+	t.Run("all", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "signed")
+
+		files, err := store.DownloadAll(testAppID, dir)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			filepath.Join(dir, "firefox-0.0.1.xpi"),
+			filepath.Join(dir, "firefox-0.0.2.xpi"),
+		}, files)
+	})
+	t.Run("invalid_version", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "signed")
+
+		mockAPI.onVersionsList = func(_ string) ([]*firefox.VersionInfo, error) {
+			return []*firefox.VersionInfo{{
+				ID:      1,
+				Version: "../../evil",
+				File:    firefox.FileInfo{Status: firefox.FileStatusPublic},
+			}}, nil
+		}
+
+		files, err := store.DownloadAll(testAppID, dir)
+		assert.ErrorContains(t, err, `version "../../evil": invalid file name`)
+		assert.Empty(t, files)
+		assert.NoDirExists(t, dir)
+	})
+}
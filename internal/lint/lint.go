@@ -0,0 +1,323 @@
+// Package lint checks extension packages against the rules of the stores
+// before they are uploaded, so that the problems are reported early.
+package lint
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/adguardteam/go-webext/internal/extstore"
+	"github.com/adguardteam/go-webext/internal/extversion"
+	"github.com/adguardteam/go-webext/internal/fileutil"
+	"github.com/adguardteam/go-webext/internal/manifest"
+)
+
+// Maximum package sizes accepted by the stores.
+const (
+	MaxSizeChrome  = 2048 * fileutil.MB
+	MaxSizeFirefox = 200 * fileutil.MB
+	MaxSizeEdge    = 200 * fileutil.MB
+)
+
+// Severity is the severity of an issue.
+type Severity string
+
+// Severities of the issues.
+const (
+	// SeverityError means the store rejects the package.
+	SeverityError Severity = "error"
+	// SeverityWarning means the package is likely to have a problem.
+	SeverityWarning Severity = "warning"
+)
+
+// Rules reported by Lint.
+const (
+	RuleArchive         = "archive"
+	RuleSize            = "size"
+	RuleManifest        = "manifest"
+	RuleManifestFields  = "manifest-fields"
+	RuleVersionFormat   = "version-format"
+	RuleVersionIncrease = "version-increase"
+	RuleGeckoID         = "gecko-id"
+	RuleDisallowedFile  = "disallowed-file"
+)
+
+// ErrUnknownStore is returned when the store has no rules.
+const ErrUnknownStore errors.Error = "unknown store"
+
+// amoVersionRe matches the versions accepted by AMO: up to four integers of
+// up to nine digits without leading zeros.
+var amoVersionRe = regexp.MustCompile(`^(0|[1-9]\d{0,8})(\.(0|[1-9]\d{0,8})){0,3}$`)
+
+// Issue is a problem found in the package.
+type Issue struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// File is the name of the file in the archive the issue is about, if any.
+	File string `json:"file,omitempty"`
+}
+
+// Report is the result of Lint.
+type Report struct {
+	Store   string  `json:"store"`
+	Path    string  `json:"path"`
+	Version string  `json:"version,omitempty"`
+	Issues  []Issue `json:"issues"`
+}
+
+// HasErrors returns true if the report contains issues with SeverityError.
+func (r *Report) HasErrors() (ok bool) {
+	for _, i := range r.Issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// add adds the issue to the report.
+func (r *Report) add(rule string, severity Severity, file, format string, args ...any) {
+	r.Issues = append(r.Issues, Issue{
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+		File:     file,
+	})
+}
+
+// Config describes the package to check.
+type Config struct {
+	// Store is the store the rules are selected for, see extstore.Chrome,
+	// extstore.Firefox and extstore.Edge.
+	Store string
+
+	// Path is the path to the zip archive.
+	Path string
+
+	// CurrentVersion is the version currently in the store.  If not empty, the
+	// version of the package must be greater.
+	CurrentVersion string
+
+	// MaxSize is the maximum size of the archive.  If zero, the limit of the
+	// store is used.
+	MaxSize int64
+}
+
+// Lint checks the package described by conf.  The problems of the package are
+// returned in the report, err is only returned if the rules can't be applied,
+// e.g. the store is unknown or the file can't be read.
+func Lint(conf Config) (r *Report, err error) {
+	maxSize, err := storeMaxSize(conf.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.MaxSize > 0 {
+		maxSize = conf.MaxSize
+	}
+
+	fi, err := os.Stat(filepath.Clean(conf.Path))
+	if err != nil {
+		return nil, fmt.Errorf("getting package info: %w", err)
+	}
+
+	r = &Report{
+		Store:  conf.Store,
+		Path:   conf.Path,
+		Issues: []Issue{},
+	}
+
+	if fi.Size() > maxSize {
+		r.add(RuleSize, SeverityError, "", "package is %d bytes, maximum is %d", fi.Size(), maxSize)
+	}
+
+	names, err := archiveNames(conf.Path)
+	if err != nil {
+		r.add(RuleArchive, SeverityError, "", "package is not a valid zip archive: %s", err)
+
+		return r, nil
+	}
+
+	checkFiles(r, names)
+	checkManifest(r, conf)
+
+	return r, nil
+}
+
+// storeMaxSize returns the maximum package size of the store.
+func storeMaxSize(store string) (size int64, err error) {
+	switch store {
+	case extstore.Chrome:
+		return MaxSizeChrome, nil
+	case extstore.Firefox:
+		return MaxSizeFirefox, nil
+	case extstore.Edge:
+		return MaxSizeEdge, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownStore, store)
+	}
+}
+
+// archiveNames returns the names of the files in the zip archive.
+func archiveNames(zipPath string) (names []string, err error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = errors.WithDeferred(err, reader.Close()) }()
+
+	for _, f := range reader.File {
+		names = append(names, f.Name)
+	}
+
+	return names, nil
+}
+
+// checkManifest checks manifest.json of the package.
+func checkManifest(r *Report, conf Config) {
+	const name = manifest.FileName
+
+	m, err := manifest.ReadZip(conf.Path)
+	if errors.Is(err, manifest.ErrNotFound) {
+		r.add(RuleManifest, SeverityError, name, "manifest.json is missing in the root of the archive")
+
+		return
+	} else if err != nil {
+		r.add(RuleManifest, SeverityError, name, "manifest.json is not valid: %s", err)
+
+		return
+	}
+
+	switch {
+	case m.Name == "":
+		r.add(RuleManifestFields, SeverityError, name, `"name" is required`)
+	case manifest.HasMessageRef(m.Name):
+		r.add(RuleManifestFields, SeverityError, name, `"name" %q references a message missing in _locales`, m.Name)
+	}
+
+	switch m.ManifestVersion {
+	case 3:
+		// Go on.
+	case 2:
+		if conf.Store != extstore.Firefox {
+			r.add(RuleManifestFields, SeverityWarning, name, "manifest version 2 is deprecated, use 3")
+		}
+	default:
+		r.add(RuleManifestFields, SeverityError, name, `"manifest_version" must be 2 or 3, got %d`, m.ManifestVersion)
+	}
+
+	if conf.Store == extstore.Firefox && m.GeckoID() == "" {
+		r.add(RuleGeckoID, SeverityError, name, `"browser_specific_settings.gecko.id" is required`)
+	}
+
+	r.Version = m.Version
+	checkVersion(r, conf, m.Version)
+}
+
+// checkVersion checks the format of the version and that it's greater than
+// the current version in the store.
+func checkVersion(r *Report, conf Config, version string) {
+	const name = manifest.FileName
+
+	if version == "" {
+		r.add(RuleManifestFields, SeverityError, name, `"version" is required`)
+
+		return
+	}
+
+	v, err := extversion.Parse(version)
+	if err == nil && conf.Store != extstore.Firefox {
+		err = v.CheckChrome()
+	}
+
+	if err != nil {
+		r.add(RuleVersionFormat, SeverityError, name, "%s", err)
+
+		return
+	}
+
+	if conf.Store == extstore.Firefox && !amoVersionRe.MatchString(version) {
+		r.add(RuleVersionFormat, SeverityError, name, "version %q has parts of more than 9 digits", version)
+
+		return
+	}
+
+	if conf.CurrentVersion == "" {
+		return
+	}
+
+	current, err := extversion.Parse(conf.CurrentVersion)
+	if err != nil {
+		r.add(RuleVersionIncrease, SeverityWarning, name, "can't compare with the store version: %s", err)
+
+		return
+	}
+
+	if extversion.Compare(v, current) <= 0 {
+		r.add(
+			RuleVersionIncrease,
+			SeverityError,
+			name,
+			"version %s must be greater than the store version %s",
+			version,
+			conf.CurrentVersion,
+		)
+	}
+}
+
+// checkFiles reports the files that must not be in the package.  Directories
+// are reported once.
+func checkFiles(r *Report, names []string) {
+	reported := map[string]bool{}
+	for _, name := range names {
+		severity, reason, file := disallowedReason(r.Store, name)
+		if reason == "" || reported[file] {
+			continue
+		}
+
+		reported[file] = true
+		r.add(RuleDisallowedFile, severity, file, "%s", reason)
+	}
+}
+
+// disallowedReason returns the severity and the reason why the file with the
+// given name must not be in the package, or an empty reason if it's allowed.
+// file is the name of the file or of its top-level directory the reason is
+// about.
+func disallowedReason(store, name string) (severity Severity, reason, file string) {
+	clean := path.Clean(name)
+	if path.IsAbs(name) || clean == ".." || strings.HasPrefix(clean, "../") || strings.Contains(name, `\`) {
+		return SeverityError, "path must be relative and use forward slashes", name
+	}
+
+	base := path.Base(clean)
+	top, _, isNested := strings.Cut(clean, "/")
+	isDir := isNested || strings.HasSuffix(name, "/")
+
+	switch {
+	case strings.HasSuffix(base, ".pem"):
+		return SeverityError, "private key must not be published", name
+	case base == ".env" || strings.HasPrefix(base, ".env."):
+		return SeverityError, "environment file may contain secrets", name
+	case top == ".git" || top == "node_modules" || top == "__MACOSX":
+		return SeverityWarning, "directory is not needed in the package", top + "/"
+	case base == ".DS_Store" || base == "Thumbs.db":
+		return SeverityWarning, "system file is not needed in the package", name
+	case store != extstore.Firefox && strings.HasPrefix(top, "_") && top != "_locales":
+		if isDir {
+			top += "/"
+		}
+
+		return SeverityError, "names starting with an underscore are reserved by the browser", top
+	default:
+		return "", "", ""
+	}
+}
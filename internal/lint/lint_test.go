@@ -0,0 +1,168 @@
+package lint_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adguardteam/go-webext/internal/extstore"
+	"github.com/adguardteam/go-webext/internal/fileutil"
+	"github.com/adguardteam/go-webext/internal/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPackage writes a zip archive with the files to a temporary directory
+// and returns its path.
+func newTestPackage(t *testing.T, files map[string]string) (zipPath string) {
+	t.Helper()
+
+	var entries []fileutil.ZipEntry
+	for name, content := range files {
+		entries = append(entries, fileutil.ZipEntry{Name: name, Data: []byte(content)})
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, fileutil.WriteZip(buf, entries))
+
+	zipPath = filepath.Join(t.TempDir(), "extension.zip")
+	require.NoError(t, os.WriteFile(zipPath, buf.Bytes(), 0o600))
+
+	return zipPath
+}
+
+// issueRules returns the rules of the issues with the given severity.
+func issueRules(r *lint.Report, severity lint.Severity) (rules []string) {
+	for _, i := range r.Issues {
+		if i.Severity == severity {
+			rules = append(rules, i.Rule)
+		}
+	}
+
+	return rules
+}
+
+const (
+	testManifestChrome  = `{"name": "Test", "version": "1.2.3", "manifest_version": 3}`
+	testManifestFirefox = `{
+	"name": "Test",
+	"version": "1.2.3",
+	"manifest_version": 2,
+	"browser_specific_settings": {"gecko": {"id": "test@example.org"}}
+}`
+)
+
+func TestLint(t *testing.T) {
+	testCases := []struct {
+		files          map[string]string
+		name           string
+		store          string
+		currentVersion string
+		wantErrors     []string
+		wantWarnings   []string
+	}{{
+		files:        map[string]string{"manifest.json": testManifestChrome},
+		name:         "valid_chrome",
+		store:        extstore.Chrome,
+		wantErrors:   nil,
+		wantWarnings: nil,
+	}, {
+		files:        map[string]string{"manifest.json": testManifestFirefox},
+		name:         "valid_firefox",
+		store:        extstore.Firefox,
+		wantErrors:   nil,
+		wantWarnings: nil,
+	}, {
+		files:        map[string]string{"background.js": ""},
+		name:         "no_manifest",
+		store:        extstore.Edge,
+		wantErrors:   []string{lint.RuleManifest},
+		wantWarnings: nil,
+	}, {
+		files:        map[string]string{"manifest.json": "{"},
+		name:         "invalid_manifest",
+		store:        extstore.Chrome,
+		wantErrors:   []string{lint.RuleManifest},
+		wantWarnings: nil,
+	}, {
+		files:        map[string]string{"manifest.json": testManifestChrome},
+		name:         "no_gecko_id",
+		store:        extstore.Firefox,
+		wantErrors:   []string{lint.RuleGeckoID},
+		wantWarnings: nil,
+	}, {
+		files: map[string]string{
+			"manifest.json": `{
+	"name": "__MSG_appName__",
+	"version": "1.2.3",
+	"manifest_version": 3,
+	"default_locale": "en"
+}`,
+			"_locales/en/messages.json": `{"appDescription": {"message": "Test"}}`,
+		},
+		name:         "missing_message",
+		store:        extstore.Chrome,
+		wantErrors:   []string{lint.RuleManifestFields},
+		wantWarnings: nil,
+	}, {
+		files: map[string]string{
+			"manifest.json": `{"name": "Test", "version": "1.02", "manifest_version": 3}`,
+		},
+		name:         "bad_version",
+		store:        extstore.Chrome,
+		wantErrors:   []string{lint.RuleVersionFormat},
+		wantWarnings: nil,
+	}, {
+		files:          map[string]string{"manifest.json": testManifestChrome},
+		name:           "version_not_increased",
+		store:          extstore.Chrome,
+		currentVersion: "1.2.3",
+		wantErrors:     []string{lint.RuleVersionIncrease},
+		wantWarnings:   nil,
+	}, {
+		files: map[string]string{
+			"manifest.json":    testManifestChrome,
+			"key.pem":          "",
+			"_metadata/a.json": "",
+			".git/HEAD":        "",
+			".git/config":      "",
+			"_locales/en.json": "",
+		},
+		name:         "disallowed_files",
+		store:        extstore.Chrome,
+		wantErrors:   []string{lint.RuleDisallowedFile, lint.RuleDisallowedFile},
+		wantWarnings: []string{lint.RuleDisallowedFile},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := lint.Lint(lint.Config{
+				Store:          tc.store,
+				Path:           newTestPackage(t, tc.files),
+				CurrentVersion: tc.currentVersion,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.wantErrors, issueRules(r, lint.SeverityError))
+			assert.Equal(t, tc.wantWarnings, issueRules(r, lint.SeverityWarning))
+			assert.Equal(t, len(tc.wantErrors) > 0, r.HasErrors())
+		})
+	}
+}
+
+func TestLint_size(t *testing.T) {
+	r, err := lint.Lint(lint.Config{
+		Store:   extstore.Firefox,
+		Path:    newTestPackage(t, map[string]string{"manifest.json": testManifestFirefox}),
+		MaxSize: 10,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{lint.RuleSize}, issueRules(r, lint.SeverityError))
+}
+
+func TestLint_unknownStore(t *testing.T) {
+	_, err := lint.Lint(lint.Config{Store: "opera"})
+	assert.ErrorIs(t, err, lint.ErrUnknownStore)
+}
@@ -0,0 +1,240 @@
+// Package manifest parses manifest.json of WebExtensions, both manifest
+// version 2 and 3, and resolves its localized strings from _locales.
+package manifest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// FileName is the name of the manifest in the root of the extension.
+const FileName = "manifest.json"
+
+// ErrNotFound is returned when the extension has no manifest.
+const ErrNotFound errors.Error = "manifest.json not found in the root of the extension"
+
+// utf8BOM is the byte order mark some editors put at the start of the file,
+// browsers accept manifests with it.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Manifest describes the fields of manifest.json used by the stores.
+type Manifest struct {
+	Name        string `json:"name"`
+	ShortName   string `json:"short_name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+	// VersionName is the human-readable version shown by Chrome and Edge
+	// instead of Version.
+	VersionName   string `json:"version_name,omitempty"`
+	DefaultLocale string `json:"default_locale,omitempty"`
+
+	// Permissions contains the API permissions and, in manifest version 2,
+	// the host permissions.
+	Permissions         []string `json:"permissions,omitempty"`
+	OptionalPermissions []string `json:"optional_permissions,omitempty"`
+	// HostPermissions contains the host permissions of manifest version 3.
+	HostPermissions []string `json:"host_permissions,omitempty"`
+	// OptionalHostPermissions contains the optional host permissions of
+	// manifest version 3.
+	OptionalHostPermissions []string `json:"optional_host_permissions,omitempty"`
+
+	// ContentScripts are injected into the pages matching their patterns,
+	// which grants the extension access to them like host permissions.
+	ContentScripts []*ContentScript `json:"content_scripts,omitempty"`
+
+	Background              *Background              `json:"background,omitempty"`
+	BrowserSpecificSettings *BrowserSpecificSettings `json:"browser_specific_settings,omitempty"`
+	// Applications is the deprecated name of BrowserSpecificSettings.
+	Applications *BrowserSpecificSettings `json:"applications,omitempty"`
+
+	ManifestVersion int `json:"manifest_version"`
+}
+
+// Background describes the background context of the extension: the service
+// worker in manifest version 3, the scripts or the page in version 2 and in
+// Firefox.
+type Background struct {
+	ServiceWorker string   `json:"service_worker,omitempty"`
+	Scripts       []string `json:"scripts,omitempty"`
+	Page          string   `json:"page,omitempty"`
+	// Type is "module" for the ES module service workers and scripts.
+	Type       string `json:"type,omitempty"`
+	Persistent *bool  `json:"persistent,omitempty"`
+}
+
+// ContentScript describes the scripts and styles injected into the pages.
+type ContentScript struct {
+	Matches []string `json:"matches,omitempty"`
+	JS      []string `json:"js,omitempty"`
+	CSS     []string `json:"css,omitempty"`
+}
+
+// BrowserSpecificSettings contains the settings of the specific browsers.
+type BrowserSpecificSettings struct {
+	Gecko *Gecko `json:"gecko,omitempty"`
+}
+
+// Gecko contains the Firefox settings.
+type Gecko struct {
+	ID               string `json:"id,omitempty"`
+	StrictMinVersion string `json:"strict_min_version,omitempty"`
+	StrictMaxVersion string `json:"strict_max_version,omitempty"`
+}
+
+// TrimBOM removes the UTF-8 byte order mark from the start of data.
+func TrimBOM(data []byte) (trimmed []byte) {
+	return bytes.TrimPrefix(data, utf8BOM)
+}
+
+// Parse parses the contents of manifest.json.  The strings aren't localized,
+// see Localize.
+func Parse(data []byte) (m *Manifest, err error) {
+	m = &Manifest{}
+	err = json.Unmarshal(TrimBOM(data), m)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", FileName, err)
+	}
+
+	return m, nil
+}
+
+// ReadFS reads the manifest from the root of fsys and localizes it using the
+// messages of the default locale, if it's set.  fsys is usually a directory
+// or a zip archive.
+func ReadFS(fsys fs.FS) (m *Manifest, err error) {
+	data, err := fs.ReadFile(fsys, FileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", FileName, err)
+	}
+
+	m, err = Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.DefaultLocale == "" {
+		return m, nil
+	}
+
+	messages, err := LoadMessages(fsys, m.DefaultLocale)
+	if err != nil {
+		return nil, fmt.Errorf("loading messages of default locale: %w", err)
+	}
+
+	m.Localize(messages)
+
+	return m, nil
+}
+
+// ReadZip reads the manifest from the zip archive of the extension, see
+// ReadFS.
+func ReadZip(zipPath string) (m *Manifest, err error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening zip file: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, reader.Close()) }()
+
+	return ReadFS(reader)
+}
+
+// Localize replaces the __MSG_*__ references in the name, the short name and
+// the description with the messages.  Unknown references are kept.
+func (m *Manifest) Localize(messages Messages) {
+	m.Name = messages.Resolve(m.Name)
+	m.ShortName = messages.Resolve(m.ShortName)
+	m.Description = messages.Resolve(m.Description)
+}
+
+// Gecko returns the Firefox settings from "applications" or, if they aren't
+// set there, from "browser_specific_settings".  It returns nil if neither is
+// set.
+func (m *Manifest) Gecko() (g *Gecko) {
+	for _, s := range []*BrowserSpecificSettings{m.Applications, m.BrowserSpecificSettings} {
+		if s != nil && s.Gecko != nil && s.Gecko.ID != "" {
+			return s.Gecko
+		}
+	}
+
+	return nil
+}
+
+// GeckoID returns the Firefox add-on ID, or an empty string if it's not set.
+func (m *Manifest) GeckoID() (id string) {
+	if g := m.Gecko(); g != nil {
+		return g.ID
+	}
+
+	return ""
+}
+
+// AllHostPermissions returns the host permissions of both manifest versions:
+// HostPermissions and the match patterns from Permissions.
+func (m *Manifest) AllHostPermissions() (hosts []string) {
+	hosts = append(hosts, m.HostPermissions...)
+	for _, p := range m.Permissions {
+		if IsHostPermission(p) {
+			hosts = append(hosts, p)
+		}
+	}
+
+	return hosts
+}
+
+// ContentScriptMatches returns the match patterns of all content scripts.
+func (m *Manifest) ContentScriptMatches() (matches []string) {
+	for _, cs := range m.ContentScripts {
+		if cs != nil {
+			matches = append(matches, cs.Matches...)
+		}
+	}
+
+	return matches
+}
+
+// APIPermissions returns the permissions which aren't host permissions.
+func (m *Manifest) APIPermissions() (perms []string) {
+	for _, p := range m.Permissions {
+		if !IsHostPermission(p) {
+			perms = append(perms, p)
+		}
+	}
+
+	return perms
+}
+
+// IsHostPermission returns true if the permission is a match pattern, e.g.
+// "<all_urls>" or "https://*.example.org/*".
+func IsHostPermission(p string) (ok bool) {
+	return p == "<all_urls>" || strings.Contains(p, "://")
+}
+
+// Validate returns an error if the manifest lacks the fields required by all
+// stores.  The errors of all fields are joined.
+func (m *Manifest) Validate() (err error) {
+	var errs []error
+	switch {
+	case m.Name == "":
+		errs = append(errs, errors.Error(`"name" is required`))
+	case HasMessageRef(m.Name):
+		errs = append(errs, fmt.Errorf(`"name" %q references a message missing in _locales`, m.Name))
+	}
+
+	if m.Version == "" {
+		errs = append(errs, errors.Error(`"version" is required`))
+	}
+
+	if m.ManifestVersion != 2 && m.ManifestVersion != 3 {
+		errs = append(errs, fmt.Errorf(`"manifest_version" must be 2 or 3, got %d`, m.ManifestVersion))
+	}
+
+	return errors.Join(errs...)
+}
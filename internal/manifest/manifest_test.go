@@ -0,0 +1,169 @@
+package manifest_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/adguardteam/go-webext/internal/manifest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifestMV3 = `{
+	"manifest_version": 3,
+	"name": "__MSG_appName__",
+	"description": "__MSG_APPDESCRIPTION__ by __MSG_author__",
+	"version": "1.2.3",
+	"version_name": "1.2.3 beta",
+	"default_locale": "en",
+	"permissions": ["storage", "tabs"],
+	"host_permissions": ["https://*.example.org/*"],
+	"optional_host_permissions": ["https://*.example.com/*"],
+	"content_scripts": [
+		{"matches": ["https://example.net/*"], "js": ["content.js"]},
+		{"matches": ["<all_urls>"], "css": ["content.css"]}
+	],
+	"background": {"service_worker": "background.js", "type": "module"},
+	"browser_specific_settings": {"gecko": {"id": "test@example.org", "strict_min_version": "109.0"}}
+}`
+
+const testMessages = `{
+	"appName": {"message": "Test Extension", "description": "Name of the extension."},
+	"appDescription": {"message": "Blocks ads"}
+}`
+
+func TestReadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		manifest.FileName:           {Data: []byte(testManifestMV3)},
+		"_locales/en/messages.json": {Data: []byte(testMessages)},
+	}
+
+	m, err := manifest.ReadFS(fsys)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, m.ManifestVersion)
+	assert.Equal(t, "Test Extension", m.Name)
+	assert.Equal(t, "Blocks ads by __MSG_author__", m.Description)
+	assert.Equal(t, "1.2.3", m.Version)
+	assert.Equal(t, "1.2.3 beta", m.VersionName)
+	assert.Equal(t, []string{"storage", "tabs"}, m.APIPermissions())
+	assert.Equal(t, []string{"https://*.example.org/*"}, m.AllHostPermissions())
+	assert.Equal(t, []string{"https://*.example.com/*"}, m.OptionalHostPermissions)
+	assert.Equal(t, []string{"https://example.net/*", "<all_urls>"}, m.ContentScriptMatches())
+	require.NotNil(t, m.Background)
+	assert.Equal(t, "background.js", m.Background.ServiceWorker)
+	assert.Equal(t, "test@example.org", m.GeckoID())
+	assert.Equal(t, "109.0", m.Gecko().StrictMinVersion)
+	assert.NoError(t, m.Validate())
+}
+
+func TestReadFS_errors(t *testing.T) {
+	t.Run("no_manifest", func(t *testing.T) {
+		_, err := manifest.ReadFS(fstest.MapFS{})
+		assert.ErrorIs(t, err, manifest.ErrNotFound)
+	})
+
+	t.Run("invalid_json", func(t *testing.T) {
+		_, err := manifest.ReadFS(fstest.MapFS{manifest.FileName: {Data: []byte(`{`)}})
+		assert.Error(t, err)
+	})
+
+	t.Run("no_messages", func(t *testing.T) {
+		_, err := manifest.ReadFS(fstest.MapFS{manifest.FileName: {Data: []byte(testManifestMV3)}})
+		assert.ErrorContains(t, err, "_locales/en/messages.json")
+	})
+}
+
+func TestParse_mv2(t *testing.T) {
+	data := []byte("\xEF\xBB\xBF" + `{
+		"manifest_version": 2,
+		"name": "Test",
+		"version": "0.0.3",
+		"permissions": ["<all_urls>", "storage", "*://*.example.org/*"],
+		"background": {"scripts": ["a.js", "b.js"], "persistent": false},
+		"applications": {"gecko": {"id": "legacy@example.org"}}
+	}`)
+
+	m, err := manifest.Parse(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"storage"}, m.APIPermissions())
+	assert.Equal(t, []string{"<all_urls>", "*://*.example.org/*"}, m.AllHostPermissions())
+	require.NotNil(t, m.Background)
+	assert.Equal(t, []string{"a.js", "b.js"}, m.Background.Scripts)
+	require.NotNil(t, m.Background.Persistent)
+	assert.False(t, *m.Background.Persistent)
+	assert.Equal(t, "legacy@example.org", m.GeckoID())
+}
+
+func TestManifest_Validate(t *testing.T) {
+	m := &manifest.Manifest{Name: "__MSG_missing__", ManifestVersion: 1}
+
+	err := m.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "references a message missing")
+	assert.ErrorContains(t, err, `"version" is required`)
+	assert.ErrorContains(t, err, `"manifest_version" must be 2 or 3`)
+}
+
+func TestMessages_Resolve(t *testing.T) {
+	msgs, err := manifest.ParseMessages([]byte(testMessages))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		in   string
+		want string
+	}{{
+		name: "exact",
+		in:   "__MSG_appName__",
+		want: "Test Extension",
+	}, {
+		name: "case_insensitive",
+		in:   "__MSG_APPNAME__",
+		want: "Test Extension",
+	}, {
+		name: "unknown",
+		in:   "__MSG_unknown__",
+		want: "__MSG_unknown__",
+	}, {
+		name: "plain",
+		in:   "Plain name",
+		want: "Plain name",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, msgs.Resolve(tc.in))
+		})
+	}
+}
+
+func TestReadZip(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "extension.zip")
+	f, err := os.Create(zipPath)
+	require.NoError(t, err)
+
+	zw := zip.NewWriter(f)
+	for name, content := range map[string]string{
+		manifest.FileName:           testManifestMV3,
+		"_locales/en/messages.json": testMessages,
+	} {
+		w, createErr := zw.Create(name)
+		require.NoError(t, createErr)
+
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	m, err := manifest.ReadZip(zipPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Test Extension", m.Name)
+}
@@ -0,0 +1,72 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// LocalesDir is the directory with the messages of the locales.
+const LocalesDir = "_locales"
+
+// messageRefRe matches the references to the messages, e.g. __MSG_appName__.
+var messageRefRe = regexp.MustCompile(`__MSG_([A-Za-z0-9_@]+?)__`)
+
+// Messages maps the lowercased names of the messages to their text, since the
+// names are case-insensitive.
+type Messages map[string]string
+
+// message is an entry of messages.json.
+type message struct {
+	Message string `json:"message"`
+}
+
+// LoadMessages reads _locales/<locale>/messages.json from fsys.
+func LoadMessages(fsys fs.FS, locale string) (messages Messages, err error) {
+	name := path.Join(LocalesDir, locale, "messages.json")
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+
+	return ParseMessages(data)
+}
+
+// ParseMessages parses the contents of messages.json.
+func ParseMessages(data []byte) (messages Messages, err error) {
+	raw := map[string]message{}
+	err = json.Unmarshal(TrimBOM(data), &raw)
+	if err != nil {
+		return nil, fmt.Errorf("parsing messages: %w", err)
+	}
+
+	messages = make(Messages, len(raw))
+	for name, msg := range raw {
+		messages[strings.ToLower(name)] = msg.Message
+	}
+
+	return messages, nil
+}
+
+// Resolve replaces the references to the messages in s.  The references to
+// unknown messages are kept as is.
+func (msgs Messages) Resolve(s string) (resolved string) {
+	return messageRefRe.ReplaceAllStringFunc(s, func(ref string) (text string) {
+		name := messageRefRe.FindStringSubmatch(ref)[1]
+		text, ok := msgs[strings.ToLower(name)]
+		if !ok {
+			return ref
+		}
+
+		return text
+	})
+}
+
+// HasMessageRef returns true if s contains a reference to a message, e.g. it
+// wasn't resolved.
+func HasMessageRef(s string) (ok bool) {
+	return messageRefRe.MatchString(s)
+}
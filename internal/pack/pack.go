@@ -0,0 +1,299 @@
+// Package pack builds the extension packages for the stores from a single
+// source directory, applying per-store overlays to the manifest.
+package pack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/adguardteam/go-webext/internal/extstore"
+	"github.com/adguardteam/go-webext/internal/fileutil"
+	"github.com/adguardteam/go-webext/internal/manifest"
+)
+
+// Config describes the package to build.
+type Config struct {
+	// Logger is used to log the progress of building the package.
+	Logger *slog.Logger
+
+	// Store is the store the package is built for, see extstore.Chrome,
+	// extstore.Firefox and extstore.Edge.
+	Store string
+
+	// Dir is the directory with the extension, manifest.json must be in its
+	// root.
+	Dir string
+
+	// Overlays contains the paths to the JSON merge patches (RFC 7396)
+	// applied to manifest.json in the given order.  The overlay files inside
+	// Dir aren't added to the package.
+	Overlays []string
+
+	// Exclude contains the patterns in the .gitignore format relative to Dir
+	// of the files not added to the package.  The .git directories are never
+	// added.
+	Exclude []string
+}
+
+// Result describes the built package.
+type Result struct {
+	// Manifest is the manifest of the package after the overlays are applied.
+	Manifest *manifest.Manifest
+	Files    int
+	Size     int64
+}
+
+// Build builds the package described by conf and atomically writes it to
+// output.  The package is reproducible, see fileutil.WriteZip.
+func Build(conf Config, output string) (res *Result, err error) {
+	l := conf.Logger.With("action", "Build", "store", conf.Store, "dir", conf.Dir)
+	l.Debug("building package")
+
+	manifestData, err := buildManifest(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := checkManifest(conf, manifestData)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := packageEntries(l, conf, output)
+	if err != nil {
+		return nil, fmt.Errorf("collecting files: %w", err)
+	}
+
+	entries = append(entries, fileutil.ZipEntry{Name: manifest.FileName, Data: manifestData})
+
+	err = fileutil.WriteFileAtomic(output, 0o644, func(f *os.File) (err error) {
+		return fileutil.WriteZip(f, entries)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("writing package %q: %w", output, err)
+	}
+
+	fi, err := os.Stat(output)
+	if err != nil {
+		return nil, fmt.Errorf("getting package info: %w", err)
+	}
+
+	l.Info("package built", "output", output, "name", m.Name, "version", m.Version, "files", len(entries))
+
+	return &Result{
+		Manifest: m,
+		Files:    len(entries),
+		Size:     fi.Size(),
+	}, nil
+}
+
+// buildManifest returns the contents of manifest.json with the overlays
+// applied.  The original contents are returned if there are no overlays.
+func buildManifest(conf Config) (data []byte, err error) {
+	data, err = os.ReadFile(filepath.Join(conf.Dir, manifest.FileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, manifest.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+
+	if len(conf.Overlays) == 0 {
+		return data, nil
+	}
+
+	doc, err := decodeJSON(manifest.TrimBOM(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", manifest.FileName, err)
+	}
+
+	for _, overlay := range conf.Overlays {
+		var patchData []byte
+		patchData, err = os.ReadFile(filepath.Clean(overlay))
+		if err != nil {
+			return nil, fmt.Errorf("reading overlay: %w", err)
+		}
+
+		var patch any
+		patch, err = decodeJSON(patchData)
+		if err != nil {
+			return nil, fmt.Errorf("parsing overlay %q: %w", overlay, err)
+		}
+
+		doc = mergePatch(doc, patch)
+	}
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	err = enc.Encode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("encoding manifest: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeJSON decodes data keeping the numbers as is.
+func decodeJSON(data []byte) (v any, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	err = dec.Decode(&v)
+
+	return v, err
+}
+
+// mergePatch applies the JSON merge patch to target as described in RFC 7396:
+// objects are merged recursively, null removes the member and any other value
+// replaces it.
+func mergePatch(target, patch any) (result any) {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+
+	return t
+}
+
+// checkManifest parses and validates the resulting manifest, localizing it
+// with the messages from Dir.
+func checkManifest(conf Config, data []byte) (m *manifest.Manifest, err error) {
+	m, err = manifest.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.DefaultLocale != "" {
+		var messages manifest.Messages
+		messages, err = manifest.LoadMessages(os.DirFS(conf.Dir), m.DefaultLocale)
+		if err != nil {
+			return nil, fmt.Errorf("loading messages of default locale: %w", err)
+		}
+
+		m.Localize(messages)
+	}
+
+	err = m.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	if conf.Store == extstore.Firefox && m.GeckoID() == "" {
+		return nil, errors.Error(`invalid manifest: "browser_specific_settings.gecko.id" is required by firefox`)
+	}
+
+	return m, nil
+}
+
+// packageEntries walks conf.Dir and returns the archive entries for the files
+// except manifest.json, the excluded files, the overlays and output.
+func packageEntries(l *slog.Logger, conf Config, output string) (entries []fileutil.ZipEntry, err error) {
+	root := filepath.Clean(conf.Dir)
+
+	skip := map[string]bool{}
+	for _, p := range append([]string{output}, conf.Overlays...) {
+		var abs string
+		abs, err = filepath.Abs(p)
+		if err != nil {
+			return nil, fmt.Errorf("getting absolute path: %w", err)
+		}
+
+		skip[abs] = true
+	}
+
+	exclude := &fileutil.Ignore{}
+	for _, p := range conf.Exclude {
+		exclude.Add("", p)
+	}
+
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return fmt.Errorf("getting relative path: %w", err)
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if rel != "." && (d.Name() == ".git" || exclude.Match(rel, true)) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if rel == manifest.FileName || exclude.Match(rel, false) {
+			return nil
+		}
+
+		if abs, absErr := filepath.Abs(p); absErr == nil && skip[abs] {
+			return nil
+		}
+
+		ok, err := isPackageFile(l, p, d)
+		if err != nil {
+			return fmt.Errorf("%s: %w", rel, err)
+		} else if !ok {
+			return nil
+		}
+
+		entries = append(entries, fileutil.ZipEntry{Name: rel, Path: p})
+
+		return nil
+	})
+
+	return entries, err
+}
+
+// isPackageFile returns true if the file should be added to the package.
+// Symlinks to regular files are followed, so that the package contains their
+// targets, while symlinks to directories and broken symlinks are errors, since
+// skipping them would silently produce an incomplete package.  Other special
+// files are skipped with a warning.
+func isPackageFile(l *slog.Logger, p string, d fs.DirEntry) (ok bool, err error) {
+	t := d.Type()
+	switch {
+	case t.IsRegular():
+		return true, nil
+	case t&fs.ModeSymlink != 0:
+		fi, statErr := os.Stat(p)
+		if statErr != nil {
+			return false, fmt.Errorf("following symlink: %w", statErr)
+		}
+
+		if !fi.Mode().IsRegular() {
+			return false, errors.Error("symlinks to directories and special files aren't supported, exclude the link")
+		}
+
+		return true, nil
+	default:
+		l.Warn("skipping special file", "path", p, "type", t.String())
+
+		return false, nil
+	}
+}
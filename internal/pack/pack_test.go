@@ -0,0 +1,219 @@
+package pack_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/adguardteam/go-webext/internal/extstore"
+	"github.com/adguardteam/go-webext/internal/fileutil"
+	"github.com/adguardteam/go-webext/internal/manifest"
+	"github.com/adguardteam/go-webext/internal/pack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `{
+	"manifest_version": 3,
+	"name": "__MSG_appName__",
+	"version": "1.2.3",
+	"default_locale": "en",
+	"permissions": ["storage"],
+	"background": {"service_worker": "background.js"}
+}`
+
+const testFirefoxOverlay = `{
+	"background": {"service_worker": null, "scripts": ["background.js"]},
+	"browser_specific_settings": {"gecko": {"id": "test@example.org"}}
+}`
+
+// newTestDir creates the extension directory with the files and returns its
+// path.
+func newTestDir(t *testing.T, files map[string]string) (dir string) {
+	t.Helper()
+
+	dir = t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+
+	return dir
+}
+
+// zipNames returns the names of the files in the archive.
+func zipNames(t *testing.T, zipPath string) (names []string) {
+	t.Helper()
+
+	r, err := zip.OpenReader(zipPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, r.Close()) })
+
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+
+	return names
+}
+
+func TestBuild(t *testing.T) {
+	dir := newTestDir(t, map[string]string{
+		"manifest.json":             testManifest,
+		"manifest.firefox.json":     testFirefoxOverlay,
+		"background.js":             "",
+		"_locales/en/messages.json": `{"appName": {"message": "Test"}}`,
+		"src/map.js.map":            "",
+		".git/HEAD":                 "",
+	})
+
+	output := filepath.Join(dir, "firefox.zip")
+	res, err := pack.Build(pack.Config{
+		Logger:   slogutil.NewDiscardLogger(),
+		Store:    extstore.Firefox,
+		Dir:      dir,
+		Overlays: []string{filepath.Join(dir, "manifest.firefox.json")},
+		Exclude:  []string{"*.map"},
+	}, output)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Test", res.Manifest.Name)
+	assert.Equal(t, "test@example.org", res.Manifest.GeckoID())
+	require.NotNil(t, res.Manifest.Background)
+	assert.Empty(t, res.Manifest.Background.ServiceWorker)
+	assert.Equal(t, []string{"background.js"}, res.Manifest.Background.Scripts)
+	assert.Equal(t, []string{"storage"}, res.Manifest.Permissions)
+
+	assert.Equal(t, []string{
+		"_locales/en/messages.json",
+		"background.js",
+		"manifest.json",
+	}, zipNames(t, output))
+
+	m, err := manifest.ReadZip(output)
+	require.NoError(t, err)
+	assert.Equal(t, 3, m.ManifestVersion)
+	assert.Equal(t, "test@example.org", m.GeckoID())
+}
+
+func TestBuild_deterministic(t *testing.T) {
+	dir := newTestDir(t, map[string]string{
+		"manifest.json":             testManifest,
+		"background.js":             "console.log(1);",
+		"_locales/en/messages.json": `{"appName": {"message": "Test"}}`,
+	})
+
+	conf := pack.Config{
+		Logger: slogutil.NewDiscardLogger(),
+		Store:  extstore.Chrome,
+		Dir:    dir,
+	}
+
+	first := filepath.Join(t.TempDir(), "first.zip")
+	_, err := pack.Build(conf, first)
+	require.NoError(t, err)
+
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "background.js"), later, later))
+
+	second := filepath.Join(t.TempDir(), "second.zip")
+	_, err = pack.Build(conf, second)
+	require.NoError(t, err)
+
+	firstData, err := os.ReadFile(first)
+	require.NoError(t, err)
+
+	secondData, err := os.ReadFile(second)
+	require.NoError(t, err)
+
+	assert.Equal(t, firstData, secondData)
+}
+
+func TestBuild_errors(t *testing.T) {
+	testCases := []struct {
+		files   map[string]string
+		name    string
+		store   string
+		wantErr string
+	}{{
+		files:   map[string]string{"background.js": ""},
+		name:    "no_manifest",
+		store:   extstore.Chrome,
+		wantErr: manifest.ErrNotFound.Error(),
+	}, {
+		files: map[string]string{
+			"manifest.json":             testManifest,
+			"_locales/en/messages.json": `{"appName": {"message": "Test"}}`,
+		},
+		name:    "no_gecko_id",
+		store:   extstore.Firefox,
+		wantErr: "gecko.id",
+	}, {
+		files:   map[string]string{"manifest.json": testManifest},
+		name:    "no_messages",
+		store:   extstore.Chrome,
+		wantErr: "messages.json",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "out.zip")
+			_, err := pack.Build(pack.Config{
+				Logger: slogutil.NewDiscardLogger(),
+				Store:  tc.store,
+				Dir:    newTestDir(t, tc.files),
+			}, output)
+			require.ErrorContains(t, err, tc.wantErr)
+
+			assert.NoFileExists(t, output)
+		})
+	}
+}
+
+func TestBuild_symlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks requires privileges on windows")
+	}
+
+	shared := newTestDir(t, map[string]string{"lib.js": "shared", "vendor/a.js": ""})
+
+	dir := newTestDir(t, map[string]string{
+		"manifest.json":             testManifest,
+		"_locales/en/messages.json": `{"appName": {"message": "Test"}}`,
+	})
+	require.NoError(t, os.Symlink(filepath.Join(shared, "lib.js"), filepath.Join(dir, "lib.js")))
+
+	conf := pack.Config{
+		Logger: slogutil.NewDiscardLogger(),
+		Store:  extstore.Chrome,
+		Dir:    dir,
+	}
+
+	output := filepath.Join(t.TempDir(), "out.zip")
+	_, err := pack.Build(conf, output)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"_locales/en/messages.json", "lib.js", "manifest.json"}, zipNames(t, output))
+
+	content, err := fileutil.ReadFileFromZip(output, "lib.js")
+	require.NoError(t, err)
+
+	assert.Equal(t, "shared", string(content))
+
+	require.NoError(t, os.Symlink(filepath.Join(shared, "vendor"), filepath.Join(dir, "vendor")))
+
+	output = filepath.Join(t.TempDir(), "out.zip")
+	_, err = pack.Build(conf, output)
+	assert.ErrorContains(t, err, "vendor: symlinks to directories")
+	assert.NoFileExists(t, output)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "vendor")))
+	require.NoError(t, os.Symlink(filepath.Join(shared, "missing.js"), filepath.Join(dir, "missing.js")))
+
+	_, err = pack.Build(conf, output)
+	assert.ErrorContains(t, err, "missing.js: following symlink")
+}
@@ -0,0 +1,258 @@
+// Package pkgdiff compares two versions of an extension package: the files,
+// the fields of the manifest and the requested permissions.
+package pkgdiff
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/adguardteam/go-webext/internal/fileutil"
+	"github.com/adguardteam/go-webext/internal/manifest"
+)
+
+// PermissionKind is the kind of permission.
+type PermissionKind string
+
+// Kinds of permissions.
+const (
+	// PermissionAPI is a required API permission, e.g. "tabs".
+	PermissionAPI PermissionKind = "api"
+	// PermissionHost is a required host permission, e.g. "<all_urls>", or a
+	// match pattern of a content script.
+	PermissionHost PermissionKind = "host"
+	// PermissionOptional is an optional permission requested at runtime.
+	PermissionOptional PermissionKind = "optional"
+)
+
+// FieldChange is a changed field of the manifest.  Old and New are the JSON
+// values, empty if the field is missing.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// PermissionChange is an added or removed permission.
+type PermissionChange struct {
+	Permission string         `json:"permission"`
+	Kind       PermissionKind `json:"kind"`
+	Added      bool           `json:"added"`
+}
+
+// Report is the result of Diff.
+type Report struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+
+	// Added, Removed and Changed are the names of the files added to, removed
+	// from and changed in the new package.
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+
+	Manifest    []FieldChange      `json:"manifest"`
+	Permissions []PermissionChange `json:"permissions"`
+}
+
+// HasEscalation returns true if the new package requests required
+// permissions the old one didn't, which usually triggers an additional
+// review by the stores.
+func (r *Report) HasEscalation() (ok bool) {
+	for _, p := range r.Permissions {
+		if p.Added && p.Kind != PermissionOptional {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Diff compares the old and the new package, which are zip archives or CRX
+// files.
+func Diff(oldPath, newPath string) (r *Report, err error) {
+	diffs, err := fileutil.CompareZips(oldPath, newPath)
+	if err != nil {
+		return nil, fmt.Errorf("comparing files: %w", err)
+	}
+
+	r = &Report{
+		Old:         oldPath,
+		New:         newPath,
+		Added:       []string{},
+		Removed:     []string{},
+		Changed:     []string{},
+		Manifest:    []FieldChange{},
+		Permissions: []PermissionChange{},
+	}
+
+	for _, d := range diffs {
+		switch d.Kind {
+		case fileutil.ZipDiffExtra:
+			r.Added = append(r.Added, d.Name)
+		case fileutil.ZipDiffMissing:
+			r.Removed = append(r.Removed, d.Name)
+		case fileutil.ZipDiffContent:
+			r.Changed = append(r.Changed, d.Name)
+		default:
+			// Only the contents matter for the review.
+		}
+	}
+
+	oldRaw, oldManifest, err := readManifest(oldPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading old manifest: %w", err)
+	}
+
+	newRaw, newManifest, err := readManifest(newPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading new manifest: %w", err)
+	}
+
+	r.Manifest = diffFields(oldRaw, newRaw)
+	r.Permissions = diffPermissions(oldManifest, newManifest)
+
+	return r, nil
+}
+
+// readManifest returns the manifest of the package both as the decoded JSON
+// and as the parsed manifest.
+func readManifest(pkgPath string) (raw map[string]any, m *manifest.Manifest, err error) {
+	a, err := fileutil.OpenZip(pkgPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { err = errors.WithDeferred(err, a.Close()) }()
+
+	data, err := fs.ReadFile(a, manifest.FileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, manifest.ErrNotFound
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("reading %s: %w", manifest.FileName, err)
+	}
+
+	m, err = manifest.Parse(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw = map[string]any{}
+	err = json.Unmarshal(manifest.TrimBOM(data), &raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing %s: %w", manifest.FileName, err)
+	}
+
+	return raw, m, nil
+}
+
+// diffFields returns the changed fields of the manifests.  Objects are
+// compared recursively with the dotted field names, other values including
+// arrays are compared as a whole.
+func diffFields(oldRaw, newRaw map[string]any) (changes []FieldChange) {
+	oldFields, newFields := map[string]string{}, map[string]string{}
+	flatten(oldFields, "", oldRaw)
+	flatten(newFields, "", newRaw)
+
+	union := maps.Clone(oldFields)
+	maps.Copy(union, newFields)
+	names := slices.Sorted(maps.Keys(union))
+
+	changes = []FieldChange{}
+	for _, name := range names {
+		if oldFields[name] != newFields[name] {
+			changes = append(changes, FieldChange{Field: name, Old: oldFields[name], New: newFields[name]})
+		}
+	}
+
+	return changes
+}
+
+// flatten adds the JSON-encoded values of the object v to fields under the
+// dotted names with the prefix.
+func flatten(fields map[string]string, prefix string, v map[string]any) {
+	for k, val := range v {
+		name := k
+		if prefix != "" {
+			name = prefix + "." + k
+		}
+
+		if obj, ok := val.(map[string]any); ok && len(obj) > 0 {
+			flatten(fields, name, obj)
+
+			continue
+		}
+
+		fields[name] = encodeValue(val)
+	}
+}
+
+// encodeValue returns the compact JSON encoding of the value decoded from
+// JSON without escaping the HTML characters, e.g. in "<all_urls>".
+func encodeValue(v any) (s string) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+
+	// Values decoded from JSON are always encodable.
+	_ = enc.Encode(v)
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// diffPermissions returns the added and removed permissions, the added ones
+// first.
+func diffPermissions(oldManifest, newManifest *manifest.Manifest) (changes []PermissionChange) {
+	oldPerms, newPerms := permissions(oldManifest), permissions(newManifest)
+
+	changes = []PermissionChange{}
+	for _, added := range []bool{true, false} {
+		from, to := oldPerms, newPerms
+		if !added {
+			from, to = newPerms, oldPerms
+		}
+
+		for _, p := range to {
+			if !slices.Contains(from, p) {
+				changes = append(changes, PermissionChange{Permission: p.name, Kind: p.kind, Added: added})
+			}
+		}
+	}
+
+	return changes
+}
+
+// permission is a permission requested by the manifest.
+type permission struct {
+	name string
+	kind PermissionKind
+}
+
+// permissions returns the permissions requested by the manifest, sorted by
+// kind and name.
+func permissions(m *manifest.Manifest) (perms []permission) {
+	for _, p := range m.APIPermissions() {
+		perms = append(perms, permission{name: p, kind: PermissionAPI})
+	}
+
+	// Content scripts get access to the matching pages just like host
+	// permissions do, and the stores review them the same way.
+	for _, p := range slices.Concat(m.AllHostPermissions(), m.ContentScriptMatches()) {
+		perms = append(perms, permission{name: p, kind: PermissionHost})
+	}
+
+	for _, p := range slices.Concat(m.OptionalPermissions, m.OptionalHostPermissions) {
+		perms = append(perms, permission{name: p, kind: PermissionOptional})
+	}
+
+	slices.SortFunc(perms, func(a, b permission) int {
+		return cmp.Or(cmp.Compare(a.kind, b.kind), cmp.Compare(a.name, b.name))
+	})
+
+	return slices.Compact(perms)
+}
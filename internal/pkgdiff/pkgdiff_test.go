@@ -0,0 +1,150 @@
+package pkgdiff_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adguardteam/go-webext/internal/fileutil"
+	"github.com/adguardteam/go-webext/internal/pkgdiff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPackage writes a zip archive with the files to a temporary directory
+// and returns its path.  If crx is true, the archive is prefixed with a CRX3
+// header.
+func newTestPackage(t *testing.T, files map[string]string, crx bool) (pkgPath string) {
+	t.Helper()
+
+	var entries []fileutil.ZipEntry
+	for name, content := range files {
+		entries = append(entries, fileutil.ZipEntry{Name: name, Data: []byte(content)})
+	}
+
+	buf := &bytes.Buffer{}
+	if crx {
+		header := []byte("signed header")
+		buf.WriteString("Cr24")
+		require.NoError(t, binary.Write(buf, binary.LittleEndian, uint32(3)))
+		require.NoError(t, binary.Write(buf, binary.LittleEndian, uint32(len(header))))
+		buf.Write(header)
+	}
+
+	require.NoError(t, fileutil.WriteZip(buf, entries))
+
+	pkgPath = filepath.Join(t.TempDir(), "extension.zip")
+	require.NoError(t, os.WriteFile(pkgPath, buf.Bytes(), 0o600))
+
+	return pkgPath
+}
+
+func TestDiff(t *testing.T) {
+	oldPath := newTestPackage(t, map[string]string{
+		"manifest.json": `{
+	"manifest_version": 3,
+	"name": "Test",
+	"version": "1.0",
+	"permissions": ["storage"],
+	"host_permissions": ["https://example.org/*"],
+	"background": {"service_worker": "bg.js"}
+}`,
+		"bg.js":     "old",
+		"same.js":   "same",
+		"legacy.js": "",
+	}, true)
+
+	newPath := newTestPackage(t, map[string]string{
+		"manifest.json": `{
+	"manifest_version": 3,
+	"name": "Test",
+	"version": "1.1",
+	"permissions": ["storage", "tabs"],
+	"optional_permissions": ["downloads"],
+	"host_permissions": ["<all_urls>"],
+	"background": {"service_worker": "background.js"}
+}`,
+		"bg.js":   "new",
+		"same.js": "same",
+		"new.js":  "",
+	}, false)
+
+	r, err := pkgdiff.Diff(oldPath, newPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"new.js"}, r.Added)
+	assert.Equal(t, []string{"legacy.js"}, r.Removed)
+	assert.Equal(t, []string{"bg.js", "manifest.json"}, r.Changed)
+
+	assert.Equal(t, []pkgdiff.FieldChange{
+		{Field: "background.service_worker", Old: `"bg.js"`, New: `"background.js"`},
+		{Field: "host_permissions", Old: `["https://example.org/*"]`, New: `["<all_urls>"]`},
+		{Field: "optional_permissions", Old: "", New: `["downloads"]`},
+		{Field: "permissions", Old: `["storage"]`, New: `["storage","tabs"]`},
+		{Field: "version", Old: `"1.0"`, New: `"1.1"`},
+	}, r.Manifest)
+
+	assert.Equal(t, []pkgdiff.PermissionChange{
+		{Permission: "tabs", Kind: pkgdiff.PermissionAPI, Added: true},
+		{Permission: "<all_urls>", Kind: pkgdiff.PermissionHost, Added: true},
+		{Permission: "downloads", Kind: pkgdiff.PermissionOptional, Added: true},
+		{Permission: "https://example.org/*", Kind: pkgdiff.PermissionHost, Added: false},
+	}, r.Permissions)
+
+	assert.True(t, r.HasEscalation())
+}
+
+func TestDiff_noEscalation(t *testing.T) {
+	files := map[string]string{
+		"manifest.json": `{"manifest_version": 2, "name": "Test", "version": "1.0", "permissions": ["tabs", "<all_urls>"]}`,
+	}
+
+	oldPath := newTestPackage(t, files, false)
+
+	files["manifest.json"] = `{"manifest_version": 2, "name": "Test", "version": "1.0", "permissions": ["tabs"]}`
+	newPath := newTestPackage(t, files, false)
+
+	r, err := pkgdiff.Diff(oldPath, newPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, []pkgdiff.PermissionChange{
+		{Permission: "<all_urls>", Kind: pkgdiff.PermissionHost, Added: false},
+	}, r.Permissions)
+	assert.False(t, r.HasEscalation())
+}
+
+func TestDiff_contentScripts(t *testing.T) {
+	files := map[string]string{
+		"manifest.json": `{
+	"manifest_version": 3,
+	"name": "Test",
+	"version": "1.0",
+	"content_scripts": [{"matches": ["https://example.org/*"], "js": ["content.js"]}]
+}`,
+	}
+
+	oldPath := newTestPackage(t, files, false)
+
+	files["manifest.json"] = `{
+	"manifest_version": 3,
+	"name": "Test",
+	"version": "1.0",
+	"optional_host_permissions": ["https://example.com/*"],
+	"content_scripts": [
+		{"matches": ["https://example.org/*"], "js": ["content.js"]},
+		{"matches": ["*://*/*"], "js": ["content.js"]}
+	]
+}`
+	newPath := newTestPackage(t, files, false)
+
+	r, err := pkgdiff.Diff(oldPath, newPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, []pkgdiff.PermissionChange{
+		{Permission: "*://*/*", Kind: pkgdiff.PermissionHost, Added: true},
+		{Permission: "https://example.com/*", Kind: pkgdiff.PermissionOptional, Added: true},
+	}, r.Permissions)
+	assert.True(t, r.HasEscalation())
+}
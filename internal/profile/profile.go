@@ -0,0 +1,83 @@
+// Package profile contains the config file with the named profiles, each
+// selecting its own credentials and item IDs, so that several products can be
+// released from one pipeline.
+package profile
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is the default path to the config file.
+const DefaultConfigFile = "go-webext.yaml"
+
+// ErrNotFound is returned when the requested profile isn't in the config file.
+const ErrNotFound errors.Error = "profile not found"
+
+// Config is the config file with the profiles.
+type Config struct {
+	// Profiles maps the names of the profiles to the profiles.
+	Profiles map[string]*Profile `yaml:"profiles"`
+}
+
+// Profile is a named set of credentials and item IDs.
+type Profile struct {
+	// Env maps the names of the environment variables, e.g.
+	// CHROME_CLIENT_ID, to their values.  The values may reference other
+	// variables as ${VAR}, which are resolved from the credential sources,
+	// so that the secrets don't need to be stored in the config file.
+	Env map[string]string `yaml:"env"`
+
+	// Apps maps the store names, see extstore.Chrome, extstore.Firefox and
+	// extstore.Edge, to the IDs of the items used when --app isn't set.
+	Apps map[string]string `yaml:"apps"`
+}
+
+// Load reads the config file at path.  JSON is accepted as well, since it's a
+// subset of YAML.
+func Load(path string) (conf *Config, err error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	conf = &Config{}
+	err = yaml.Unmarshal(data, conf)
+	if err != nil {
+		return nil, fmt.Errorf("parsing config file %q: %w", path, err)
+	}
+
+	for name, p := range conf.Profiles {
+		if p == nil {
+			conf.Profiles[name] = &Profile{}
+		}
+	}
+
+	return conf, nil
+}
+
+// Profile returns the profile with the given name.  It returns an error
+// wrapping ErrNotFound if there is no such profile.
+func (c *Config) Profile(name string) (p *Profile, err error) {
+	p, ok := c.Profiles[name]
+	if !ok {
+		names := slices.Sorted(maps.Keys(c.Profiles))
+
+		return nil, fmt.Errorf("%w: %q, available: %s", ErrNotFound, name, strings.Join(names, ", "))
+	}
+
+	return p, nil
+}
+
+// AppID returns the ID of the item in the store with the given name.  It
+// returns an empty string if the profile doesn't have one.
+func (p *Profile) AppID(store string) (id string) {
+	return p.Apps[store]
+}
@@ -0,0 +1,50 @@
+package profile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adguardteam/go-webext/internal/extstore"
+	"github.com/adguardteam/go-webext/internal/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+profiles:
+  adguard:
+    env:
+      CHROME_PUBLISHER_ID: publisher
+      FIREFOX_CLIENT_SECRET: ${ADGUARD_AMO_SECRET}
+    apps:
+      chrome: chrome-item
+      firefox: firefox-addon
+  adguard-beta:
+`
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), profile.DefaultConfigFile)
+	require.NoError(t, os.WriteFile(path, []byte(testConfig), 0o600))
+
+	conf, err := profile.Load(path)
+	require.NoError(t, err)
+
+	p, err := conf.Profile("adguard")
+	require.NoError(t, err)
+
+	assert.Equal(t, "publisher", p.Env["CHROME_PUBLISHER_ID"])
+	assert.Equal(t, "${ADGUARD_AMO_SECRET}", p.Env["FIREFOX_CLIENT_SECRET"])
+	assert.Equal(t, "chrome-item", p.AppID(extstore.Chrome))
+	assert.Equal(t, "firefox-addon", p.AppID(extstore.Firefox))
+	assert.Empty(t, p.AppID(extstore.Edge))
+
+	p, err = conf.Profile("adguard-beta")
+	require.NoError(t, err)
+
+	assert.Empty(t, p.AppID(extstore.Chrome))
+
+	_, err = conf.Profile("unknown")
+	assert.ErrorIs(t, err, profile.ErrNotFound)
+	assert.ErrorContains(t, err, "adguard, adguard-beta")
+}
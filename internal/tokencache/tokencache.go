@@ -0,0 +1,63 @@
+// Package tokencache caches OAuth access tokens until they are about to expire.
+package tokencache
+
+import (
+	"sync"
+	"time"
+)
+
+// RefreshMargin is the time before the expiry of the token when it's
+// considered expired, so that it doesn't expire in the middle of a request.
+const RefreshMargin = 1 * time.Minute
+
+// FetchFunc retrieves a new access token and its lifetime.  Zero lifetime
+// means that the token mustn't be cached.
+type FetchFunc func() (token string, expiresIn time.Duration, err error)
+
+// Cache is an access token cache safe for concurrent use.  The token is
+// fetched at most once at a time, concurrent callers wait for it.
+type Cache struct {
+	// now returns the current time.
+	now func() time.Time
+
+	// mu protects token and expiresAt and serializes fetches.
+	mu        *sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// New returns a new token cache.  If now is nil, time.Now is used.
+func New(now func() time.Time) (c *Cache) {
+	if now == nil {
+		now = time.Now
+	}
+
+	return &Cache{
+		now: now,
+		mu:  &sync.Mutex{},
+	}
+}
+
+// Token returns the cached token if it's valid for at least RefreshMargin,
+// otherwise it fetches and caches a new one.
+func (c *Cache) Token(fetch FetchFunc) (token string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && c.now().Add(RefreshMargin).Before(c.expiresAt) {
+		return c.token, nil
+	}
+
+	token, expiresIn, err := fetch()
+	if err != nil {
+		return "", err
+	}
+
+	if expiresIn > 0 {
+		c.token, c.expiresAt = token, c.now().Add(expiresIn)
+	} else {
+		c.token, c.expiresAt = "", time.Time{}
+	}
+
+	return token, nil
+}
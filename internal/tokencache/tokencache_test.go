@@ -0,0 +1,84 @@
+package tokencache_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adguardteam/go-webext/internal/tokencache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_Token(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	c := tokencache.New(func() time.Time { return now })
+
+	fetches := 0
+	fetch := func() (string, time.Duration, error) {
+		fetches++
+
+		return "token" + strconv.Itoa(fetches), time.Hour, nil
+	}
+
+	token, err := c.Token(fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "token1", token)
+
+	now = now.Add(30 * time.Minute)
+	token, err = c.Token(fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "token1", token)
+
+	// Within the refresh margin the token is considered expired.
+	now = now.Add(30*time.Minute - tokencache.RefreshMargin)
+	token, err = c.Token(fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "token2", token)
+}
+
+func TestCache_Token_noExpiry(t *testing.T) {
+	c := tokencache.New(nil)
+
+	fetches := 0
+	fetch := func() (string, time.Duration, error) {
+		fetches++
+
+		return "token", 0, nil
+	}
+
+	for range 2 {
+		_, err := c.Token(fetch)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, fetches)
+}
+
+func TestCache_Token_concurrent(t *testing.T) {
+	c := tokencache.New(nil)
+
+	mu := &sync.Mutex{}
+	fetches := 0
+	fetch := func() (string, time.Duration, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		fetches++
+
+		return "token", time.Hour, nil
+	}
+
+	wg := &sync.WaitGroup{}
+	for range 10 {
+		wg.Go(func() {
+			token, err := c.Token(fetch)
+			assert.NoError(t, err)
+			assert.Equal(t, "token", token)
+		})
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, fetches)
+}